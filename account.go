@@ -7,21 +7,35 @@ import "golang.org/x/net/context"
 //See: http://docs.stormpath.com/rest/product-guide/#accounts
 type Account struct {
 	customDataAwareResource
-	Username               string            `json:"username"`
-	Email                  string            `json:"email"`
-	Password               string            `json:"password,omitempty"`
-	FullName               string            `json:"fullName,omitempty"`
-	GivenName              string            `json:"givenName"`
-	MiddleName             string            `json:"middleName,omitempty"`
-	Surname                string            `json:"surname"`
-	Status                 string            `json:"status,omitempty"`
-	Groups                 *Groups           `json:"groups,omitempty"`
-	GroupMemberships       *GroupMemberships `json:"groupMemberships,omitempty"`
-	Directory              *Directory        `json:"directory,omitempty"`
-	Tenant                 *Tenant           `json:"tenant,omitempty"`
-	EmailVerificationToken *resource         `json:"emailVerificationToken,omitempty"`
+	Username                string            `json:"username"`
+	Email                   string            `json:"email"`
+	Password                string            `json:"password,omitempty"`
+	FullName                string            `json:"fullName,omitempty"`
+	GivenName               string            `json:"givenName"`
+	MiddleName              string            `json:"middleName,omitempty"`
+	Surname                 string            `json:"surname"`
+	Status                  string            `json:"status,omitempty"`
+	EmailVerificationStatus string            `json:"emailVerificationStatus,omitempty"`
+	Groups                  *Groups           `json:"groups,omitempty"`
+	GroupMemberships        *GroupMemberships `json:"groupMemberships,omitempty"`
+	Directory               *Directory        `json:"directory,omitempty"`
+	Tenant                  *Tenant           `json:"tenant,omitempty"`
+	EmailVerificationToken  *resource         `json:"emailVerificationToken,omitempty"`
+	APIKeys                 *APIKeys          `json:"apiKeys,omitempty"`
+	AccessTokens            *AccessTokens     `json:"accessTokens,omitempty"`
+	RefreshTokens           *RefreshTokens    `json:"refreshTokens,omitempty"`
+	ProviderData            *ProviderData     `json:"providerData,omitempty"`
+	Factors                 *Factors          `json:"factors,omitempty"`
+	Phones                  *Phones           `json:"phones,omitempty"`
 }
 
+//Account email verification statuses
+const (
+	EmailVerified            = "VERIFIED"
+	EmailUnverified          = "UNVERIFIED"
+	EmailVerificationUnknown = "UNKNOWN"
+)
+
 //Accounts represents a paged result of Account objects
 //
 //See: http://docs.stormpath.com/rest/product-guide/#accounts-collectionResource
@@ -34,9 +48,10 @@ type Accounts struct {
 //
 //See: http://docs.stormpath.com/rest/product-guide/#application-accounts (Reset An Account’s Password)
 type AccountPasswordResetToken struct {
-	Href    string
-	Email   string
-	Account Account
+	Href         string    `json:"href"`
+	Email        string    `json:"email"`
+	Account      Account   `json:"account"`
+	AccountStore *resource `json:"accountStore,omitempty"`
 }
 
 type accountRef struct {
@@ -49,10 +64,27 @@ type SocialAccount struct {
 	Data ProviderData `json:"providerData"`
 }
 
-//ProviderData represents the especific information needed by the social provider (Google, Github, Faceboo, etc)
+//ProviderData represents the especific information needed by the social provider (Google, Github, Faceboo, etc).
+//Either an AccessToken or an authorization Code must be given, Google, GitHub and LinkedIn accept both,
+//Facebook only accepts an AccessToken. RefreshToken is only set by Stormpath for Google accounts
+//
+//See: http://docs.stormpath.com/rest/product-guide/#integrating-with-google
 type ProviderData struct {
-	ProviderID  string `json:"providerId"`
-	AccessToken string `json:"accessToken,omitempty"`
+	resource
+	ProviderID   string `json:"providerId"`
+	AccessToken  string `json:"accessToken,omitempty"`
+	Code         string `json:"code,omitempty"`
+	RefreshToken string `json:"refreshToken,omitempty"`
+}
+
+//NewSocialAccountWithAccessToken creates the payload to register an account with a social provider access token
+func NewSocialAccountWithAccessToken(providerID string, accessToken string) *SocialAccount {
+	return &SocialAccount{Data: ProviderData{ProviderID: providerID, AccessToken: accessToken}}
+}
+
+//NewSocialAccountWithCode creates the payload to register an account with a social provider authorization code
+func NewSocialAccountWithCode(providerID string, code string) *SocialAccount {
+	return &SocialAccount{Data: ProviderData{ProviderID: providerID, Code: code}}
 }
 
 //NewAccount returns a pointer to an Account with the minimum data required
@@ -163,6 +195,78 @@ func (account *Account) GetGroupMemberships(ctx context.Context, criteria Criter
 	return groupMemberships, nil
 }
 
+//GetProviderData loads the provider data of the account, for social accounts it holds
+//the provider ID and the tokens of the account at the provider
+//
+//See: http://docs.stormpath.com/rest/product-guide/#accessing-accounts-with-google-authorization-codes-or-an-access-tokens
+func (account *Account) GetProviderData(ctx context.Context) (*ProviderData, error) {
+	providerData := &ProviderData{}
+
+	err := getClient(ctx).get(account.ProviderData.Href, emptyPayload(), providerData)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return providerData, nil
+}
+
+//CreateAPIKey creates a new API key pair for the given account, the returned key is the only time
+//the secret is available so it should be handed to the account owner right away
+//
+//See: http://docs.stormpath.com/rest/product-guide/#account-api-keys
+func (account *Account) CreateAPIKey(ctx context.Context) (*APIKey, error) {
+	apiKey := &APIKey{}
+
+	err := getClient(ctx).post(buildAbsoluteURL(account.Href, "apiKeys"), emptyPayload(), apiKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+//GetAPIKeys returns a paged result of the API keys of the given account
+//
+//See: http://docs.stormpath.com/rest/product-guide/#account-api-keys
+func (account *Account) GetAPIKeys(ctx context.Context, criteria Criteria) (*APIKeys, error) {
+	apiKeys := &APIKeys{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(account.APIKeys.Href, criteria.ToQueryString()),
+		emptyPayload(),
+		apiKeys,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+//IsEnabled returns true if the account status is ENABLED
+func (account *Account) IsEnabled() bool {
+	return account.Status == Enabled
+}
+
+//IsDisabled returns true if the account was disabled by an administrator
+func (account *Account) IsDisabled() bool {
+	return account.Status == Disabled
+}
+
+//IsUnverified returns true if the account can't login because its email hasn't been verified yet,
+//the verification email can be sent again with Application.ResendVerificationEmail
+func (account *Account) IsUnverified() bool {
+	return account.Status == Unverified
+}
+
+//IsEmailVerified returns true if the account email has been verified
+func (account *Account) IsEmailVerified() bool {
+	return account.EmailVerificationStatus == EmailVerified
+}
+
 //VerifyEmailToken verifies an email verification token associated with an account
 //
 //See: http://docs.stormpath.com/rest/product-guide/#account-verify-email
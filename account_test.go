@@ -18,6 +18,33 @@ var _ = Describe("Account", func() {
 			Expect(string(jsonData)).To(Equal("{\"username\":\"test@test.org\",\"email\":\"test@test.org\",\"password\":\"123\",\"givenName\":\"test\",\"surname\":\"test\"}"))
 		})
 	})
+	Describe("SocialAccount", func() {
+		It("should marshal the provider access token", func() {
+			socialAccount := NewSocialAccountWithAccessToken(FacebookProviderID, "token")
+
+			jsonData, _ := json.Marshal(socialAccount)
+
+			Expect(string(jsonData)).To(Equal("{\"providerData\":{\"providerId\":\"facebook\",\"accessToken\":\"token\"}}"))
+		})
+		It("should marshal the provider authorization code", func() {
+			socialAccount := NewSocialAccountWithCode(GoogleProviderID, "code")
+
+			jsonData, _ := json.Marshal(socialAccount)
+
+			Expect(string(jsonData)).To(Equal("{\"providerData\":{\"providerId\":\"google\",\"code\":\"code\"}}"))
+		})
+	})
+	Describe("GetProviderData", func() {
+		It("should return the stormpath provider data for a cloud account", func() {
+			account := registerTestAccount(ctx)
+
+			providerData, err := account.GetProviderData(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(providerData.Href).NotTo(BeEmpty())
+			Expect(providerData.ProviderID).To(Equal(StormpathProviderID))
+		})
+	})
 	Describe("GetAccount", func() {
 		It("should return an error if the account doesn't exists", func() {
 			account, err := GetAccount(ctx, BaseURL + "/accounts/xxxxxx", MakeAccountCriteria())
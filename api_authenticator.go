@@ -0,0 +1,258 @@
+package stormpath
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/nu7hatch/gouuid"
+	"golang.org/x/net/context"
+)
+
+//API authentication errors
+var (
+	ErrInvalidAuthorizationHeader = errors.New("invalid or missing Authorization header")
+	ErrInvalidAPIKeyCredentials   = errors.New("invalid API key credentials")
+	ErrAPIKeyDisabled             = errors.New("API key is disabled")
+	ErrAccountDisabled            = errors.New("account is disabled")
+)
+
+//ScopeFactory decides which of the requested scopes are granted to an access token issued for an API key,
+//returning an error denies the token request
+type ScopeFactory func(ctx context.Context, result *APIAuthenticationResult, requestedScopes []string) ([]string, error)
+
+//DefaultClientCredentialsTokenTTL is the time to live of the access tokens issued by GetClientCredentialsToken
+//when ClientCredentialsOptions.TokenTTL is not set
+const DefaultClientCredentialsTokenTTL = 1 * time.Hour
+
+//ClientCredentialsOptions configures the access tokens issued by GetClientCredentialsToken, the requested scopes
+//are granted by ScopeFactory, when nil no scopes are granted, and TokenTTL defaults to DefaultClientCredentialsTokenTTL
+type ClientCredentialsOptions struct {
+	ScopeFactory ScopeFactory
+	TokenTTL     time.Duration
+}
+
+//APIAuthenticationResult holds the outcome of a successful API request authentication,
+//Scopes are only set when the request was authenticated with a Bearer access token
+type APIAuthenticationResult struct {
+	Account *Account
+	APIKey  *APIKey
+	Scopes  []string
+}
+
+//apiKeyCacheEntry is what gets stored in the cache for an API key lookup, the secret itself is never
+//cached only its SHA-256 hash, which is enough to validate the credentials of following requests
+type apiKeyCacheEntry struct {
+	APIKey     APIKey `json:"apiKey"`
+	SecretHash string `json:"secretHash"`
+}
+
+//AuthenticateAPIRequest authenticates an incoming HTTP request made with an account API key, either using
+//HTTP Basic authentication "Authorization: Basic base64(id:secret)" or a Bearer access token obtained
+//by exchanging the API key. Both the API key and its account must be enabled.
+//
+//API key lookups are cached when the client has a cache, so disabling or deleting a key would only take effect
+//once the cache entry expires.
+//
+//See: http://docs.stormpath.com/guides/api-key-management/
+func (app *Application) AuthenticateAPIRequest(ctx context.Context, r *http.Request) (*APIAuthenticationResult, error) {
+	scheme, value := parseAuthorizationHeader(r.Header.Get(AuthorizationHeader))
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		return app.authenticateBasic(ctx, value)
+	case "bearer":
+		return app.authenticateBearer(ctx, value)
+	}
+
+	return nil, ErrInvalidAuthorizationHeader
+}
+
+func (app *Application) authenticateBasic(ctx context.Context, value string) (*APIAuthenticationResult, error) {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidAuthorizationHeader
+	}
+
+	credentials := strings.SplitN(string(decoded), ":", 2)
+	if len(credentials) != 2 || credentials[0] == "" || credentials[1] == "" {
+		return nil, ErrInvalidAuthorizationHeader
+	}
+
+	return app.authenticateAPIKey(ctx, credentials[0], credentials[1])
+}
+
+//authenticateAPIKey validates an API key ID and secret pair, the secret is compared in constant time
+func (app *Application) authenticateAPIKey(ctx context.Context, id string, secret string) (*APIAuthenticationResult, error) {
+	entry, err := app.lookupAPIKey(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashSecret(secret)), []byte(entry.SecretHash)) != 1 {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+
+	return newAPIAuthenticationResult(&entry.APIKey)
+}
+
+func (app *Application) authenticateBearer(ctx context.Context, value string) (*APIAuthenticationResult, error) {
+	client := getClient(ctx)
+
+	token, err := jwt.Parse(value, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, ErrInvalidAPIKeyCredentials
+		}
+		return []byte(client.Credentials.Secret), nil
+	})
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+
+	//Like the access tokens validated locally the token must expire and be issued by this application
+	if _, ok := token.Claims["exp"].(float64); !ok {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+
+	if iss, _ := token.Claims["iss"].(string); iss != app.Href {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+
+	apiKeyID, ok := token.Claims["sub"].(string)
+	if !ok || apiKeyID == "" {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+
+	entry, err := app.lookupAPIKey(ctx, apiKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := newAPIAuthenticationResult(&entry.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if scope, ok := token.Claims["scope"].(string); ok && scope != "" {
+		result.Scopes = strings.Split(scope, " ")
+	}
+
+	return result, nil
+}
+
+//GetClientCredentialsToken exchanges an account API key for an OAuth2 access token (client_credentials grant).
+//The token is a JWT signed with the client credentials, its subject is the API key ID and it can be use
+//as a Bearer token with AuthenticateAPIRequest. The optional ClientCredentialsOptions grant the requested scopes
+//and set the token time to live.
+//
+//See: http://docs.stormpath.com/guides/api-key-management/#exchanging-an-api-key-for-an-access-token
+func (app *Application) GetClientCredentialsToken(ctx context.Context, apiKeyID string, apiKeySecret string, scopes []string, options ...ClientCredentialsOptions) (*OAuthResponse, error) {
+	result, err := app.authenticateAPIKey(ctx, apiKeyID, apiKeySecret)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := DefaultClientCredentialsTokenTTL
+	var scopeFactory ScopeFactory
+	if len(options) > 0 {
+		scopeFactory = options[0].ScopeFactory
+		if options[0].TokenTTL > 0 {
+			ttl = options[0].TokenTTL
+		}
+	}
+
+	var grantedScopes []string
+	if scopeFactory != nil {
+		grantedScopes, err = scopeFactory(ctx, result, scopes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	nonce, _ := uuid.NewV4()
+
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Claims["jti"] = nonce.String()
+	token.Claims["iat"] = now.Unix()
+	token.Claims["exp"] = now.Add(ttl).Unix()
+	token.Claims["iss"] = app.Href
+	token.Claims["sub"] = result.APIKey.ID
+	if len(grantedScopes) > 0 {
+		token.Claims["scope"] = strings.Join(grantedScopes, " ")
+	}
+
+	tokenString, err := token.SignedString([]byte(getClient(ctx).Credentials.Secret))
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthResponse{
+		AccessToken: tokenString,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		Scope:       strings.Join(grantedScopes, " "),
+	}, nil
+}
+
+//lookupAPIKey fetches the API key with the given ID from the cache or from Stormpath
+func (app *Application) lookupAPIKey(ctx context.Context, id string) (*apiKeyCacheEntry, error) {
+	client := getClient(ctx)
+	key := app.Href + "#apiKey=" + id
+
+	entry := &apiKeyCacheEntry{}
+	if client.Cache != nil && client.Cache.Exists(key) {
+		err := client.Cache.Get(key, entry)
+		if err == nil {
+			return entry, nil
+		}
+	}
+
+	apiKey, err := app.GetAPIKey(ctx, id)
+	if err == ErrAPIKeyNotFound {
+		return nil, ErrInvalidAPIKeyCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	entry.APIKey = *apiKey
+	entry.SecretHash = hashSecret(apiKey.Secret)
+
+	if client.Cache != nil {
+		client.Cache.Set(key, entry)
+	}
+
+	return entry, nil
+}
+
+func newAPIAuthenticationResult(apiKey *APIKey) (*APIAuthenticationResult, error) {
+	if apiKey.Status != Enabled {
+		return nil, ErrAPIKeyDisabled
+	}
+	if apiKey.Account == nil || apiKey.Account.Status != Enabled {
+		return nil, ErrAccountDisabled
+	}
+
+	return &APIAuthenticationResult{Account: apiKey.Account, APIKey: apiKey}, nil
+}
+
+//parseAuthorizationHeader splits an Authorization header value into its scheme and credentials
+func parseAuthorizationHeader(header string) (string, string) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return parts[0], strings.TrimSpace(parts[1])
+}
+
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,178 @@
+package stormpath_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"golang.org/x/net/context"
+)
+
+func newAPIRequest(authorization string) *http.Request {
+	req, _ := http.NewRequest("GET", "http://localhost:8080/api", nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return req
+}
+
+func bearerAuthorization(claims map[string]interface{}) string {
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Claims["iss"] = app.Href
+	token.Claims["sub"] = "apiKeyID"
+	token.Claims["exp"] = time.Now().Add(time.Hour).Unix()
+	for name, value := range claims {
+		if value == nil {
+			delete(token.Claims, name)
+		} else {
+			token.Claims[name] = value
+		}
+	}
+	tokenString, _ := token.SignedString([]byte(cred.Secret))
+	return "Bearer " + tokenString
+}
+
+func basicAuthorization(id string, secret string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(id+":"+secret))
+}
+
+var _ = Describe("AuthenticateAPIRequest", func() {
+	It("should return an error if the Authorization header is missing", func() {
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(""))
+
+		Expect(err).To(Equal(ErrInvalidAuthorizationHeader))
+		Expect(result).To(BeNil())
+	})
+
+	It("should return an error if the Authorization scheme is not supported", func() {
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest("Digest abc"))
+
+		Expect(err).To(Equal(ErrInvalidAuthorizationHeader))
+		Expect(result).To(BeNil())
+	})
+
+	It("should return an error if the Basic credentials are malformed", func() {
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest("Basic "+base64.StdEncoding.EncodeToString([]byte("id"))))
+
+		Expect(err).To(Equal(ErrInvalidAuthorizationHeader))
+		Expect(result).To(BeNil())
+	})
+
+	It("should return an error if the Bearer token is invalid", func() {
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest("Bearer invalid"))
+
+		Expect(err).To(Equal(ErrInvalidAPIKeyCredentials))
+		Expect(result).To(BeNil())
+	})
+
+	It("should return an error if the Bearer token has no expiration", func() {
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(bearerAuthorization(map[string]interface{}{"exp": nil})))
+
+		Expect(err).To(Equal(ErrInvalidAPIKeyCredentials))
+		Expect(result).To(BeNil())
+	})
+
+	It("should return an error if the Bearer token was not issued by the application", func() {
+		for _, iss := range []interface{}{nil, "https://api.stormpath.com/v1/applications/other"} {
+			result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(bearerAuthorization(map[string]interface{}{"iss": iss})))
+
+			Expect(err).To(Equal(ErrInvalidAPIKeyCredentials))
+			Expect(result).To(BeNil())
+		}
+	})
+
+	It("should authenticate a request with valid Basic API key credentials", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(basicAuthorization(apiKey.ID, apiKey.Secret)))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Account.Href).To(Equal(account.Href))
+		Expect(result.APIKey.Href).To(Equal(apiKey.Href))
+		apiKey.Delete(ctx)
+	})
+
+	It("should return an error if the API key secret is wrong", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(basicAuthorization(apiKey.ID, "wrong")))
+
+		Expect(err).To(Equal(ErrInvalidAPIKeyCredentials))
+		Expect(result).To(BeNil())
+		apiKey.Delete(ctx)
+	})
+
+	It("should return an error if the API key is disabled", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+		apiKey.Disable(ctx)
+
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest(basicAuthorization(apiKey.ID, apiKey.Secret)))
+
+		Expect(err).To(Equal(ErrAPIKeyDisabled))
+		Expect(result).To(BeNil())
+		apiKey.Delete(ctx)
+	})
+})
+
+var _ = Describe("GetClientCredentialsToken", func() {
+	It("should return an error if the API key credentials are invalid", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+
+		response, err := app.GetClientCredentialsToken(ctx, apiKey.ID, "wrong", nil)
+
+		Expect(err).To(Equal(ErrInvalidAPIKeyCredentials))
+		Expect(response).To(BeNil())
+		apiKey.Delete(ctx)
+	})
+
+	It("should issue an access token usable as a Bearer token", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+
+		response, err := app.GetClientCredentialsToken(ctx, apiKey.ID, apiKey.Secret, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.AccessToken).NotTo(BeEmpty())
+		Expect(response.TokenType).To(Equal("Bearer"))
+		Expect(response.ExpiresIn).To(Equal(3600))
+		Expect(response.Scope).To(BeEmpty())
+
+		result, err := app.AuthenticateAPIRequest(ctx, newAPIRequest("Bearer "+response.AccessToken))
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.APIKey.ID).To(Equal(apiKey.ID))
+		Expect(result.Account.Href).To(Equal(account.Href))
+		apiKey.Delete(ctx)
+	})
+
+	It("should grant the scopes returned by the ScopeFactory", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+		scopeFactory := func(ctx context.Context, result *APIAuthenticationResult, requestedScopes []string) ([]string, error) {
+			return requestedScopes[:1], nil
+		}
+
+		response, err := app.GetClientCredentialsToken(ctx, apiKey.ID, apiKey.Secret, []string{"read", "write"}, ClientCredentialsOptions{ScopeFactory: scopeFactory})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.Scope).To(Equal("read"))
+
+		result, _ := app.AuthenticateAPIRequest(ctx, newAPIRequest("Bearer "+response.AccessToken))
+
+		Expect(result.Scopes).To(Equal([]string{"read"}))
+		apiKey.Delete(ctx)
+	})
+
+	It("should issue an access token with the given time to live", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+
+		response, err := app.GetClientCredentialsToken(ctx, apiKey.ID, apiKey.Secret, nil, ClientCredentialsOptions{TokenTTL: 5 * time.Minute})
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.ExpiresIn).To(Equal(300))
+		apiKey.Delete(ctx)
+	})
+})
@@ -0,0 +1,110 @@
+package stormpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"golang.org/x/net/context"
+)
+
+//ErrAPIKeyNotFound is returned when an API key lookup doesn't match any key
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+//secretPattern matches the secret attributes of JSON encoded resources (API keys, directory providers)
+var secretPattern = regexp.MustCompile(`"(secret|clientSecret|agentUserDnPassword)"\s*:\s*"[^"]*"`)
+
+//APIKey represents a Stormpath account API key object, the secret is only held in memory,
+//it is never included when the key is serialized for caching nor written to the logs
+//
+//See: http://docs.stormpath.com/rest/product-guide/#account-api-keys
+type APIKey struct {
+	resource
+	ID          string   `json:"id,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	Account     *Account `json:"account,omitempty"`
+	Tenant      *Tenant  `json:"tenant,omitempty"`
+}
+
+//APIKeys represents a paged result of APIKey objects
+type APIKeys struct {
+	collectionResource
+	Items []APIKey `json:"items"`
+}
+
+//rawAPIKey is an alias of APIKey without the custom JSON marshaling
+type rawAPIKey APIKey
+
+//MarshalJSON implements json.Marshaler, the secret is always left out of the JSON document
+func (key APIKey) MarshalJSON() ([]byte, error) {
+	k := rawAPIKey(key)
+	k.Secret = ""
+	return json.Marshal(k)
+}
+
+//String implements fmt.Stringer with the secret redacted
+func (key APIKey) String() string {
+	return fmt.Sprintf("APIKey{Href: %s, ID: %s, Secret: %s, Status: %s}", key.Href, key.ID, redacted(key.Secret), key.Status)
+}
+
+//GoString implements fmt.GoStringer so %#v doesn't leak the secret either
+func (key APIKey) GoString() string {
+	return key.String()
+}
+
+//GetAPIKey loads an API key by href and criteria
+func GetAPIKey(ctx context.Context, href string, criteria Criteria) (*APIKey, error) {
+	apiKey := &APIKey{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(href, criteria.ToQueryString()),
+		emptyPayload(),
+		apiKey,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (key *APIKey) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(key.Href, emptyPayload(), key)
+}
+
+//Update updates the given resource, by doing a POST to the resource Href,
+//only the name, description and status can be updated
+func (key *APIKey) Update(ctx context.Context) error {
+	return getClient(ctx).post(key.Href, key, key)
+}
+
+//Enable sets the API key status to ENABLED
+func (key *APIKey) Enable(ctx context.Context) error {
+	key.Status = Enabled
+	return key.Update(ctx)
+}
+
+//Disable sets the API key status to DISABLED, a disabled key can't be use to authenticate
+func (key *APIKey) Disable(ctx context.Context) error {
+	key.Status = Disabled
+	return key.Update(ctx)
+}
+
+//redacted masks a secret value so it can be safely logged
+func redacted(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "*****"
+}
+
+//redactSecrets masks any secret from a raw HTTP dump before it is logged
+func redactSecrets(dump []byte) []byte {
+	return secretPattern.ReplaceAll(dump, []byte(`"$1":"*****"`))
+}
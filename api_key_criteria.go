@@ -0,0 +1,55 @@
+package stormpath
+
+import "net/url"
+
+type APIKeyCriteria struct {
+	baseCriteria
+}
+
+func MakeAPIKeyCriteria() APIKeyCriteria {
+	return APIKeyCriteria{baseCriteria{filter: url.Values{}}}
+}
+
+func MakeAPIKeysCriteria() APIKeyCriteria {
+	return APIKeyCriteria{baseCriteria{limit: 25, filter: url.Values{}}}
+}
+
+//Filter related functions
+
+//Possible filters:
+//* id
+//* name
+//* description
+//* status
+
+func (c APIKeyCriteria) IDEq(id string) APIKeyCriteria {
+	c.filter.Add("id", id)
+	return c
+}
+
+func (c APIKeyCriteria) NameEq(name string) APIKeyCriteria {
+	c.filter.Add("name", name)
+	return c
+}
+
+func (c APIKeyCriteria) DescriptionEq(description string) APIKeyCriteria {
+	c.filter.Add("description", description)
+	return c
+}
+
+func (c APIKeyCriteria) StatusEq(status string) APIKeyCriteria {
+	c.filter.Add("status", status)
+	return c
+}
+
+//Expansion related functions
+
+func (c APIKeyCriteria) WithAccount() APIKeyCriteria {
+	c.expandedAttributes = append(c.expandedAttributes, "account")
+	return c
+}
+
+func (c APIKeyCriteria) WithTenant() APIKeyCriteria {
+	c.expandedAttributes = append(c.expandedAttributes, "tenant")
+	return c
+}
@@ -0,0 +1,96 @@
+package stormpath_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("APIKey", func() {
+	Describe("JSON", func() {
+		It("should never marshal the secret", func() {
+			apiKey := APIKey{ID: "id", Secret: "secret", Status: Enabled}
+
+			jsonData, _ := json.Marshal(apiKey)
+
+			Expect(string(jsonData)).To(Equal("{\"id\":\"id\",\"status\":\"ENABLED\"}"))
+		})
+
+		It("should unmarshal the secret", func() {
+			apiKey := &APIKey{}
+
+			err := json.Unmarshal([]byte("{\"id\":\"id\",\"secret\":\"secret\"}"), apiKey)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apiKey.Secret).To(Equal("secret"))
+		})
+	})
+
+	Describe("String", func() {
+		It("should redact the secret", func() {
+			apiKey := APIKey{ID: "id", Secret: "secret"}
+
+			Expect(fmt.Sprintf("%v", apiKey)).NotTo(ContainSubstring("secret"))
+			Expect(fmt.Sprintf("%#v", apiKey)).NotTo(ContainSubstring("secret"))
+		})
+	})
+
+	Describe("account API keys", func() {
+		It("should create a new API key with its secret", func() {
+			apiKey, err := account.CreateAPIKey(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apiKey.Href).NotTo(BeEmpty())
+			Expect(apiKey.ID).NotTo(BeEmpty())
+			Expect(apiKey.Secret).NotTo(BeEmpty())
+			Expect(apiKey.Status).To(Equal(Enabled))
+			apiKey.Delete(ctx)
+		})
+
+		It("should list the account API keys", func() {
+			apiKey, _ := account.CreateAPIKey(ctx)
+
+			apiKeys, err := account.GetAPIKeys(ctx, MakeAPIKeysCriteria())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apiKeys.Items).NotTo(BeEmpty())
+			apiKey.Delete(ctx)
+		})
+
+		It("should disable and enable an API key", func() {
+			apiKey, _ := account.CreateAPIKey(ctx)
+
+			err := apiKey.Disable(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apiKey.Status).To(Equal(Disabled))
+
+			err = apiKey.Enable(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(apiKey.Status).To(Equal(Enabled))
+			apiKey.Delete(ctx)
+		})
+	})
+
+	Describe("application API key lookup", func() {
+		It("should find an API key by ID", func() {
+			apiKey, _ := account.CreateAPIKey(ctx)
+
+			k, err := app.GetAPIKey(ctx, apiKey.ID)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k.Href).To(Equal(apiKey.Href))
+			Expect(k.Account.Href).To(Equal(account.Href))
+			apiKey.Delete(ctx)
+		})
+
+		It("should return ErrAPIKeyNotFound if the key doesn't exists", func() {
+			k, err := app.GetAPIKey(ctx, "xxxxxx")
+
+			Expect(err).To(Equal(ErrAPIKeyNotFound))
+			Expect(k).To(BeNil())
+		})
+	})
+})
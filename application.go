@@ -2,12 +2,8 @@ package stormpath
 
 import (
 	"encoding/base64"
-	"errors"
 	"net/url"
-	"time"
 
-	"github.com/dgrijalva/jwt-go"
-	"github.com/nu7hatch/gouuid"
 	"golang.org/x/net/context"
 )
 
@@ -25,6 +21,9 @@ type Application struct {
 	AccountStoreMappings       *AccountStoreMappings `json:"accountStoreMappings,omitempty"`
 	DefaultAccountStoreMapping *AccountStoreMapping  `json:"defaultAccountStoreMapping,omitempty"`
 	DefaultGroupStoreMapping   *AccountStoreMapping  `json:"defaultGroupStoreMapping,omitempty"`
+	OAuthPolicy                *OAuthPolicy          `json:"oAuthPolicy,omitempty"`
+	SAMLPolicy                 *SAMLPolicy           `json:"samlPolicy,omitempty"`
+	AuthorizedCallbackURIs     []string              `json:"authorizedCallbackUris,omitempty"`
 }
 
 //Applications represents a paged result or applications
@@ -33,14 +32,6 @@ type Applications struct {
 	Items []Application `json:"items"`
 }
 
-//IDSiteCallbackResult holds the ID Site callback parsed JWT token information + the acccount if one was given
-type IDSiteCallbackResult struct {
-	Account *Account
-	State   string
-	IsNew   bool
-	Status  string
-}
-
 //OAuthResponse represents an OAuth2 response from StormPath
 type OAuthResponse struct {
 	AccessToken              string `json:"access_token"`
@@ -48,6 +39,7 @@ type OAuthResponse struct {
 	TokenType                string `json:"token_type"`
 	ExpiresIn                int    `json:"expires_in"`
 	StormpathAccessTokenHref string `json:"stormpath_access_token_href"`
+	Scope                    string `json:"scope,omitempty"`
 }
 
 type AccessToken struct {
@@ -148,40 +140,126 @@ func (app *Application) RegisterSocialAccount(ctx context.Context, socialAccount
 	return account, nil
 }
 
-//AuthenticateAccount authenticates an account against the application
+//AuthenticationOptions holds the optional settings of a login attempt.
+//
+//AccountStoreHref, OrganizationHref and OrganizationNameKey restrict the login attempt to a single account store
+//mapped to the application, in that order of precedence. AccountCriteria expands the authenticated account,
+//when nil the account is expanded in the login attempt response, when set the account is loaded with the criteria
+//expansions (customData, groups, etc.) since a login attempt can only expand the account itself.
+//
+//ProviderData is only used by GetOAuthToken, when set the social provider access token or authorization code
+//is exchanged for the OAuth2 tokens (stormpath_social grant) instead of the username and password.
+type AuthenticationOptions struct {
+	AccountStoreHref    string
+	OrganizationNameKey string
+	OrganizationHref    string
+	AccountCriteria     Criteria
+	ProviderData        *ProviderData
+}
+
+//accountStore returns the accountStore attribute of a login attempt for the given options or nil if
+//the login attempt is not scoped
+func (options AuthenticationOptions) accountStore() map[string]string {
+	if options.AccountStoreHref != "" {
+		return map[string]string{"href": options.AccountStoreHref}
+	}
+	if options.OrganizationHref != "" {
+		return map[string]string{"href": options.OrganizationHref}
+	}
+	if options.OrganizationNameKey != "" {
+		return map[string]string{"nameKey": options.OrganizationNameKey}
+	}
+	return nil
+}
+
+//AuthenticateAccount authenticates an account against the application, the optional AuthenticationOptions
+//restricts the login attempt to a single account store or organization and sets the account expansions
 //
 //See: http://docs.stormpath.com/rest/product-guide/#authenticate-an-account
-func (app *Application) AuthenticateAccount(ctx context.Context, username string, password string) (*Account, error) {
+func (app *Application) AuthenticateAccount(ctx context.Context, username string, password string, options ...AuthenticationOptions) (*Account, error) {
+	opts := AuthenticationOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
 	accountRef := &accountRef{Account: &Account{}}
 
-	loginAttemptPayload := make(map[string]string)
+	loginAttemptPayload := make(map[string]interface{})
 	loginAttemptPayload["type"] = "basic"
 	loginAttemptPayload["value"] = base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	if accountStore := opts.accountStore(); accountStore != nil {
+		loginAttemptPayload["accountStore"] = accountStore
+	}
 
-	err := getClient(ctx).post(buildAbsoluteURL(app.Href, "loginAttempts"), loginAttemptPayload, accountRef)
+	err := getClient(ctx).post(buildAbsoluteURL(app.Href, "loginAttempts")+"?expand=account", loginAttemptPayload, accountRef)
 
 	if err != nil {
 		return nil, err
 	}
 
-	account := accountRef.Account
-	err = account.Refresh(ctx)
-	if err != nil {
-		return nil, err
+	if opts.AccountCriteria != nil {
+		return GetAccount(ctx, accountRef.Account.Href, opts.AccountCriteria)
 	}
 
-	return account, nil
+	return accountRef.Account, nil
 }
 
-//GetOAuthToken creates a OAuth2 token response for a given user credentials
-func (app *Application) GetOAuthToken(ctx context.Context, username string, password string) (*OAuthResponse, error) {
-	response := &OAuthResponse{}
+//GetOAuthToken creates a OAuth2 token response for a given user credentials, the optional AuthenticationOptions
+//restricts the authentication to a single account store or organization.
+//
+//With the ProviderData option the social provider access token or authorization code is exchanged instead
+//(stormpath_social grant), the username and password are ignored and the account is created in the provider
+//directory if it doesn't exist yet.
+//
+//See: http://docs.stormpath.com/guides/token-management/#social-token-exchange
+func (app *Application) GetOAuthToken(ctx context.Context, username string, password string, options ...AuthenticationOptions) (*OAuthResponse, error) {
+	opts := AuthenticationOptions{}
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	values := url.Values{}
+	if opts.ProviderData != nil {
+		values.Set("grant_type", "stormpath_social")
+		values.Set("providerId", opts.ProviderData.ProviderID)
+		if opts.ProviderData.AccessToken != "" {
+			values.Set("accessToken", opts.ProviderData.AccessToken)
+		}
+		if opts.ProviderData.Code != "" {
+			values.Set("code", opts.ProviderData.Code)
+		}
+	} else {
+		values.Set("grant_type", "password")
+		values.Set("username", username)
+		values.Set("password", password)
+	}
 
-	values := url.Values{
-		"grant_type": {"password"},
-		"username":   {username},
-		"password":   {password},
+	switch {
+	case opts.AccountStoreHref != "":
+		values.Set("accountStore", opts.AccountStoreHref)
+	case opts.OrganizationHref != "":
+		values.Set("accountStore", opts.OrganizationHref)
+	case opts.OrganizationNameKey != "":
+		values.Set("organizationNameKey", opts.OrganizationNameKey)
 	}
+
+	return app.getOAuthToken(ctx, values)
+}
+
+//RefreshOAuthToken exchanges a refresh token for a new OAuth2 access token (refresh_token grant)
+//
+//See: http://docs.stormpath.com/guides/token-management/#refreshing-access-tokens
+func (app *Application) RefreshOAuthToken(ctx context.Context, refreshToken string) (*OAuthResponse, error) {
+	return app.getOAuthToken(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+//getOAuthToken posts the given grant to the application OAuth token endpoint
+func (app *Application) getOAuthToken(ctx context.Context, values url.Values) (*OAuthResponse, error) {
+	response := &OAuthResponse{}
+
 	body := canonicalizeQueryString(values)
 
 	err := getClient(ctx).postURLEncodedForm(
@@ -197,7 +275,7 @@ func (app *Application) GetOAuthToken(ctx context.Context, username string, pass
 	return response, nil
 }
 
-//Validate Token against Application
+//ValidateToken validates an access token against Stormpath, see ValidateTokenWithStrategy for local validation
 func (app *Application) ValidateToken(ctx context.Context, token string) (*AccessToken, error) {
 	response := &AccessToken{}
 
@@ -214,14 +292,43 @@ func (app *Application) ValidateToken(ctx context.Context, token string) (*Acces
 	return response, nil
 }
 
-//SendPasswordResetEmail sends a password reset email to the given user
+//ResendVerificationEmail sends the email verification email again to an unverified account, login is
+//the account username or email. The accountStore href is optional, when set the account is only looked up
+//in that account store instead of all the application account stores.
+//
+//See: http://docs.stormpath.com/rest/product-guide/#account-verify-email
+func (app *Application) ResendVerificationEmail(ctx context.Context, login string, accountStore string) error {
+	verificationEmailPayload := make(map[string]interface{})
+	verificationEmailPayload["login"] = login
+	if accountStore != "" {
+		verificationEmailPayload["accountStore"] = map[string]string{"href": accountStore}
+	}
+
+	client := getClient(ctx)
+
+	//The response is a 202 Accepted without a body, do closes it
+	return client.do(client.newRequest("POST", buildAbsoluteURL(app.Href, "verificationEmails"), verificationEmailPayload, ApplicationJson))
+}
+
+//PasswordResetOptions holds the optional settings of the password reset workflow, AccountStoreHref restricts
+//the account lookup to a single account store and ExpandAccount returns the fully populated account on reset
+type PasswordResetOptions struct {
+	AccountStoreHref string
+	ExpandAccount    bool
+}
+
+//SendPasswordResetEmail sends a password reset email to the given user, the optional PasswordResetOptions
+//restricts the account lookup to a given account store
 //
 //See: http://docs.stormpath.com/rest/product-guide/#reset-an-accounts-password
-func (app *Application) SendPasswordResetEmail(ctx context.Context, email string) (*AccountPasswordResetToken, error) {
+func (app *Application) SendPasswordResetEmail(ctx context.Context, email string, options ...PasswordResetOptions) (*AccountPasswordResetToken, error) {
 	passwordResetToken := &AccountPasswordResetToken{}
 
-	passwordResetPayload := make(map[string]string)
+	passwordResetPayload := make(map[string]interface{})
 	passwordResetPayload["email"] = email
+	if len(options) > 0 && options[0].AccountStoreHref != "" {
+		passwordResetPayload["accountStore"] = map[string]string{"href": options[0].AccountStoreHref}
+	}
 
 	err := getClient(ctx).post(buildAbsoluteURL(app.Href, "passwordResetTokens"), passwordResetPayload, passwordResetToken)
 
@@ -247,21 +354,32 @@ func (app *Application) ValidatePasswordResetToken(ctx context.Context, token st
 	return passwordResetToken, nil
 }
 
-//ResetPassword resets a user password based on the reset token
+//ResetPassword resets a user password based on the reset token, with the ExpandAccount option
+//the returned account is fully populated otherwise only its Href is set
 //
 //See: http://docs.stormpath.com/rest/product-guide/#reset-an-accounts-password
-func (app *Application) ResetPassword(ctx context.Context, token string, newPassword string) (*Account, error) {
+func (app *Application) ResetPassword(ctx context.Context, token string, newPassword string, options ...PasswordResetOptions) (*Account, error) {
 	accountRef := &accountRef{}
-	account := &Account{}
 
 	resetPasswordPayload := make(map[string]string)
 	resetPasswordPayload["password"] = newPassword
 
-	err := getClient(ctx).post(buildAbsoluteURL(app.Href, "passwordResetTokens", token), resetPasswordPayload, accountRef)
+	resetURL := buildAbsoluteURL(app.Href, "passwordResetTokens", token)
+	if len(options) > 0 && options[0].ExpandAccount {
+		resetURL += "?expand=account"
+	}
+
+	err := getClient(ctx).post(resetURL, resetPasswordPayload, accountRef)
 
 	if err != nil {
 		return nil, err
 	}
+
+	if len(options) > 0 && options[0].ExpandAccount {
+		return accountRef.Account, nil
+	}
+
+	account := &Account{}
 	account.Href = accountRef.Account.Href
 
 	return account, nil
@@ -293,79 +411,26 @@ func (app *Application) GetGroups(ctx context.Context, criteria Criteria) (*Grou
 	return groups, nil
 }
 
-//CreateIDSiteURL creates the IDSite URL for the application
-func (app *Application) CreateIDSiteURL(ctx context.Context, options map[string]string) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	nonce, _ := uuid.NewV4()
-
-	if options["path"] == "" {
-		options["path"] = "/"
-	}
-
-	token.Claims["jti"] = nonce.String()
-	token.Claims["iat"] = time.Now().Unix()
-	token.Claims["iss"] = getClient(ctx).Credentials.ID
-	token.Claims["sub"] = app.Href
-	token.Claims["state"] = options["state"]
-	token.Claims["path"] = options["path"]
-	token.Claims["cb_uri"] = options["callbackURI"]
-
-	tokenString, err := token.SignedString([]byte(getClient(ctx).Credentials.Secret))
-	if err != nil {
-		return "", err
-	}
-
-	p, _ := url.Parse(app.Href)
-	ssoURL := p.Scheme + "://" + p.Host + "/sso"
-
-	if options["logout"] == "true" {
-		ssoURL = ssoURL + "/logout" + "?jwtRequest=" + tokenString
-	} else {
-		ssoURL = ssoURL + "?jwtRequest=" + tokenString
-	}
-
-	return ssoURL, nil
-}
-
-//HandleIDSiteCallback handles the URL from an ID Site callback it parses the JWT token
-//validates it and return an IDSiteCallbackResult with the token info + the Account if the sub was given
-func (app *Application) HandleIDSiteCallback(ctx context.Context, URL string) (*IDSiteCallbackResult, error) {
-	result := &IDSiteCallbackResult{}
-
-	cbURL, err := url.Parse(URL)
-	if err != nil {
-		return nil, err
-	}
+//GetAPIKey looks up an API key by its ID, the key must belong to an account of one of the application
+//account stores. The account of the key is expanded so its status can be checked without an extra request
+//
+//See: http://docs.stormpath.com/rest/product-guide/#application-api-keys
+func (app *Application) GetAPIKey(ctx context.Context, id string) (*APIKey, error) {
+	apiKeys := &APIKeys{}
 
-	jwtResponse := cbURL.Query().Get("jwtResponse")
+	err := getClient(ctx).get(
+		buildAbsoluteURL(app.Href, "apiKeys")+MakeAPIKeyCriteria().IDEq(id).WithAccount().ToQueryString(),
+		emptyPayload(),
+		apiKeys,
+	)
 
-	token, err := jwt.Parse(jwtResponse, func(token *jwt.Token) (interface{}, error) {
-		return []byte(getClient(ctx).Credentials.Secret), nil
-	})
 	if err != nil {
 		return nil, err
 	}
 
-	if token.Claims["aud"].(string) != getClient(ctx).Credentials.ID {
-		return nil, errors.New("ID Site invalid aud")
-	}
-
-	if time.Now().Unix() > int64(token.Claims["exp"].(float64)) {
-		return nil, errors.New("ID Site JWT has expired")
-	}
-
-	if token.Claims["sub"] != nil {
-		account, err := GetAccount(ctx, token.Claims["sub"].(string), MakeAccountCriteria())
-		if err != nil {
-			return nil, err
-		}
-		result.Account = account
-	}
-	if token.Claims["state"] != nil {
-		result.State = token.Claims["state"].(string)
+	if len(apiKeys.Items) == 0 {
+		return nil, ErrAPIKeyNotFound
 	}
-	result.Status = token.Claims["status"].(string)
 
-	return result, nil
+	return &apiKeys.Items[0], nil
 }
@@ -44,6 +44,13 @@ var _ = Describe("Application", func() {
 			_, err := app.ValidateToken(ctx,"anInvalidToken")
 			Expect(err).To(HaveOccurred())
 		})
+
+		It("should return an error exchanging an invalid social provider access token", func() {
+			response, err := app.GetOAuthToken(ctx, "", "", AuthenticationOptions{ProviderData: &ProviderData{ProviderID: FacebookProviderID, AccessToken: "anInvalidToken"}})
+
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+		})
 	})
 
 	Describe("JSON", func() {
@@ -87,6 +94,38 @@ var _ = Describe("Application", func() {
 			Expect(a.Surname).To(Equal(account.Surname))
 			Expect(a.Email).To(Equal(account.Email))
 		})
+
+		It("should authenticate the account against the given account store", func() {
+			account := registerTestAccount(ctx)
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{AccountStoreHref: account.Directory.Href})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Href).To(Equal(account.Href))
+		})
+
+		It("should not authenticate the account against an account store that doesn't contain it", func() {
+			account := registerTestAccount(ctx)
+			dir := newTestDirectory()
+			tenant.CreateDirectory(ctx, dir)
+			NewAccountStoreMapping(app.Href, dir.Href).Save(ctx)
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{AccountStoreHref: dir.Href})
+
+			Expect(err).To(HaveOccurred())
+			Expect(a).To(BeNil())
+			dir.Delete(ctx)
+		})
+
+		It("should return the account with the requested expansions", func() {
+			account := registerTestAccount(ctx)
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{AccountCriteria: MakeAccountCriteria().WithCustomData()})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Href).To(Equal(account.Href))
+			Expect(a.CustomData).NotTo(BeNil())
+		})
 	})
 
 	Describe("groups", func() {
@@ -123,6 +162,40 @@ var _ = Describe("Application", func() {
 			})
 		})
 	})
+	Describe("ResendVerificationEmail", func() {
+		It("should resend the verification email to an unverified account", func() {
+			dir := newTestDirectory()
+			tenant.CreateDirectory(ctx, dir)
+			NewAccountStoreMapping(app.Href, dir.Href).Save(ctx)
+			policy, _ := dir.GetAccountCreationPolicy(ctx)
+			policy.VerificationEmailStatus = Enabled
+			policy.Update(ctx)
+
+			account := newTestAccount()
+			dir.RegisterAccount(ctx, account)
+
+			Expect(account.IsUnverified()).To(BeTrue())
+			Expect(account.IsDisabled()).To(BeFalse())
+			Expect(account.IsEmailVerified()).To(BeFalse())
+
+			err := app.ResendVerificationEmail(ctx, account.Email, dir.Href)
+
+			Expect(err).NotTo(HaveOccurred())
+			dir.Delete(ctx)
+		})
+
+		It("should return an error if the login doesn't exist in the account store", func() {
+			dir := newTestDirectory()
+			tenant.CreateDirectory(ctx, dir)
+			NewAccountStoreMapping(app.Href, dir.Href).Save(ctx)
+
+			err := app.ResendVerificationEmail(ctx, randomName()+"@test.org", dir.Href)
+
+			Expect(err).To(HaveOccurred())
+			dir.Delete(ctx)
+		})
+	})
+
 	Describe("password reset", func() {
 		Describe("SendPasswordResetEmail", func() {
 			It("should create a new password reset token", func() {
@@ -131,6 +204,28 @@ var _ = Describe("Application", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(token.Href).NotTo(BeEmpty())
 			})
+
+			It("should create a password reset token scoped to the account store", func() {
+				account := registerTestAccount(ctx)
+
+				token, err := app.SendPasswordResetEmail(ctx, account.Email, PasswordResetOptions{AccountStoreHref: account.Directory.Href})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(token.Email).To(Equal(account.Email))
+				Expect(token.Account.Href).To(Equal(account.Href))
+			})
+
+			It("should return an error if the account is not in the account store", func() {
+				account := registerTestAccount(ctx)
+				dir := newTestDirectory()
+				tenant.CreateDirectory(ctx, dir)
+				NewAccountStoreMapping(app.Href, dir.Href).Save(ctx)
+
+				_, err := app.SendPasswordResetEmail(ctx, account.Email, PasswordResetOptions{AccountStoreHref: dir.Href})
+
+				Expect(err).To(HaveOccurred())
+				dir.Delete(ctx)
+			})
 		})
 
 		Describe("ResetPassword", func() {
@@ -144,6 +239,19 @@ var _ = Describe("Application", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(a.Href).To(Equal(account.Href))
 			})
+
+			It("should return the expanded account", func() {
+				account := registerTestAccount(ctx)
+				token, _ := app.SendPasswordResetEmail(ctx, account.Email)
+
+				re := regexp.MustCompile("[^\\/]+$")
+
+				a, err := app.ResetPassword(ctx, re.FindString(token.Href), "8787987!kJKJdfW", PasswordResetOptions{ExpandAccount: true})
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(a.Href).To(Equal(account.Href))
+				Expect(a.Email).To(Equal(account.Email))
+			})
 		})
 
 		Describe("ValidatePasswordResetToken", func() {
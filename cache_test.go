@@ -101,6 +101,9 @@ var _ = Describe("Cacheable", func() {
 				&Groups{},
 				&Directories{},
 				&AccountStoreMappings{},
+				&APIKeys{},
+				&Organizations{},
+				&Factors{},
 			}
 			for _, resource := range resources {
 				c, ok := resource.(Cacheable)
@@ -120,6 +123,14 @@ var _ = Describe("Cacheable", func() {
 				&Directory{},
 				&AccountStoreMapping{},
 				&Tenant{},
+				&APIKey{},
+				&OAuthPolicy{},
+				&SAMLPolicy{},
+				&Organization{},
+				&Factor{},
+				&Challenge{},
+				&PasswordPolicy{},
+				&PasswordStrength{},
 			}
 			for _, resource := range resources {
 				c, ok := resource.(Cacheable)
@@ -129,4 +140,20 @@ var _ = Describe("Cacheable", func() {
 			}
 		})
 	})
+
+	Describe("EvictCache", func() {
+		It("should evict the expanded response of a resource", func() {
+			account := registerTestAccount(ctx)
+			criteria := MakeAccountCriteria().WithCustomData()
+
+			GetAccount(ctx, account.Href, criteria)
+			account.UpdateCustomData(ctx, CustomData{"plan": "pro"})
+			EvictCache(account.Href, criteria)
+
+			expanded, err := GetAccount(ctx, account.Href, criteria)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect((*expanded.CustomData)["plan"]).To(Equal("pro"))
+		})
+	})
 })
\ No newline at end of file
@@ -1,6 +1,10 @@
 package stormpath
 
-import "golang.org/x/net/context"
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+)
 
 //Directory represents a Stormpath directory object
 //
@@ -13,8 +17,13 @@ type Directory struct {
 	Groups                *Groups                `json:"groups,omitempty"`
 	Tenant                *Tenant                `json:"tenant,omitempty"`
 	AccountCreationPolicy *AccountCreationPolicy `json:"accountCreationPolicy,omitempty"`
+	PasswordPolicy        *PasswordPolicy        `json:"passwordPolicy,omitempty"`
+	Provider              DirectoryProvider      `json:"provider,omitempty"`
 }
 
+//rawDirectory is an alias of Directory without the custom JSON unmarshaling
+type rawDirectory Directory
+
 //Directories represnets a paged result of directories
 type Directories struct {
 	collectionResource
@@ -26,6 +35,32 @@ func NewDirectory(name string) *Directory {
 	return &Directory{Name: name}
 }
 
+//NewDirectoryWithProvider creates a new directory with the given name backed by the given provider,
+//e.g. a social, LDAP or SAML directory
+func NewDirectoryWithProvider(name string, provider DirectoryProvider) *Directory {
+	return &Directory{Name: name, Provider: provider}
+}
+
+//UnmarshalJSON implements json.Unmarshaler, the directory provider is decoded into its concrete type
+func (dir *Directory) UnmarshalJSON(data []byte) error {
+	d := struct {
+		*rawDirectory
+		Provider json.RawMessage `json:"provider,omitempty"`
+	}{rawDirectory: (*rawDirectory)(dir)}
+
+	err := json.Unmarshal(data, &d)
+	if err != nil {
+		return err
+	}
+
+	dir.Provider = nil
+	if len(d.Provider) > 0 && string(d.Provider) != "null" {
+		dir.Provider, err = unmarshalProvider(d.Provider)
+	}
+
+	return err
+}
+
 //GetDirectory loads a directory by href and criteria
 func GetDirectory(ctx context.Context, href string, criteria Criteria) (*Directory, error) {
 	directory := &Directory{}
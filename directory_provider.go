@@ -0,0 +1,227 @@
+package stormpath
+
+import (
+	"encoding/json"
+
+	"golang.org/x/net/context"
+)
+
+//Directory provider IDs
+const (
+	StormpathProviderID       = "stormpath"
+	GoogleProviderID          = "google"
+	FacebookProviderID        = "facebook"
+	GitHubProviderID          = "github"
+	LinkedInProviderID        = "linkedin"
+	LDAPProviderID            = "ldap"
+	ActiveDirectoryProviderID = "ad"
+	SAMLProviderID            = "saml"
+)
+
+//DirectoryProvider is implemented by all the directory provider types, the concrete type can be found
+//with a type switch or by its provider ID
+type DirectoryProvider interface {
+	GetProviderID() string
+}
+
+//Provider represents the basic attributes of any directory provider, it is also the provider type
+//of Stormpath cloud directories
+//
+//See: http://docs.stormpath.com/rest/product-guide/#directory-provider
+type Provider struct {
+	resource
+	ProviderID string `json:"providerId,omitempty"`
+}
+
+//GetProviderID returns the ID of the provider (stormpath, google, facebook, github, linkedin, ldap, ad or saml)
+func (p *Provider) GetProviderID() string {
+	return p.ProviderID
+}
+
+//GoogleProvider represents the configuration of a Google directory
+type GoogleProvider struct {
+	Provider
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	RedirectURI  string `json:"redirectUri"`
+	HostedDomain string `json:"hd,omitempty"`
+}
+
+//FacebookProvider represents the configuration of a Facebook directory
+type FacebookProvider struct {
+	Provider
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+//GitHubProvider represents the configuration of a GitHub directory
+type GitHubProvider struct {
+	Provider
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+}
+
+//LinkedInProvider represents the configuration of a LinkedIn directory
+type LinkedInProvider struct {
+	Provider
+	ClientID     string `json:"clientId"`
+	ClientSecret string `json:"clientSecret"`
+	RedirectURI  string `json:"redirectUri,omitempty"`
+}
+
+//LDAPProvider represents the configuration of an LDAP or Active Directory mirror directory
+//
+//See: http://docs.stormpath.com/rest/product-guide/#ldap-directories
+type LDAPProvider struct {
+	Provider
+	Agent *LDAPAgent `json:"agent,omitempty"`
+}
+
+//LDAPAgent represents the Stormpath agent that synchronizes an LDAP directory
+type LDAPAgent struct {
+	resource
+	ID     string          `json:"id,omitempty"`
+	Status string          `json:"status,omitempty"`
+	Config LDAPAgentConfig `json:"config"`
+}
+
+//LDAPAgentConfig holds the settings the agent uses to connect to the LDAP server and map its entries
+type LDAPAgentConfig struct {
+	DirectoryHost        string            `json:"directoryHost"`
+	DirectoryPort        int               `json:"directoryPort"`
+	SSLRequired          bool              `json:"sslRequired"`
+	AgentUserDN          string            `json:"agentUserDn"`
+	AgentUserDNPassword  string            `json:"agentUserDnPassword,omitempty"`
+	BaseDN               string            `json:"baseDn"`
+	PollInterval         int               `json:"pollInterval"`
+	ReferralMode         string            `json:"referralMode,omitempty"`
+	IgnoreReferralIssues bool              `json:"ignoreReferralIssues"`
+	AccountConfig        LDAPAccountConfig `json:"accountConfig"`
+	GroupConfig          LDAPGroupConfig   `json:"groupConfig"`
+}
+
+//LDAPAccountConfig maps LDAP entries to Stormpath accounts
+type LDAPAccountConfig struct {
+	DNSuffix      string `json:"dnSuffix,omitempty"`
+	ObjectClass   string `json:"objectClass"`
+	ObjectFilter  string `json:"objectFilter,omitempty"`
+	EmailRDN      string `json:"emailRdn"`
+	GivenNameRDN  string `json:"givenNameRdn"`
+	MiddleNameRDN string `json:"middleNameRdn,omitempty"`
+	SurnameRDN    string `json:"surnameRdn"`
+	UsernameRDN   string `json:"usernameRdn"`
+	PasswordRDN   string `json:"passwordRdn"`
+}
+
+//LDAPGroupConfig maps LDAP entries to Stormpath groups
+type LDAPGroupConfig struct {
+	DNSuffix       string `json:"dnSuffix,omitempty"`
+	ObjectClass    string `json:"objectClass"`
+	ObjectFilter   string `json:"objectFilter,omitempty"`
+	NameRDN        string `json:"nameRdn"`
+	DescriptionRDN string `json:"descriptionRdn"`
+	MembersRDN     string `json:"membersRdn"`
+}
+
+//SAMLProvider represents the configuration of a SAML directory, which delegates the authentication to a SAML IdP
+//
+//See: http://docs.stormpath.com/rest/product-guide/#saml-directories
+type SAMLProvider struct {
+	Provider
+	SSOLoginURL                    string    `json:"ssoLoginUrl"`
+	SSOLogoutURL                   string    `json:"ssoLogoutUrl,omitempty"`
+	EncodedX509SigningCert         string    `json:"encodedX509SigningCert"`
+	RequestSignatureAlgorithm      string    `json:"requestSignatureAlgorithm"`
+	ServiceProviderMetadata        *resource `json:"serviceProviderMetadata,omitempty"`
+	AttributeStatementMappingRules *resource `json:"attributeStatementMappingRules,omitempty"`
+}
+
+//NewGoogleProvider creates a new Google directory provider
+func NewGoogleProvider(clientID string, clientSecret string, redirectURI string) *GoogleProvider {
+	return &GoogleProvider{Provider: Provider{ProviderID: GoogleProviderID}, ClientID: clientID, ClientSecret: clientSecret, RedirectURI: redirectURI}
+}
+
+//NewFacebookProvider creates a new Facebook directory provider
+func NewFacebookProvider(clientID string, clientSecret string) *FacebookProvider {
+	return &FacebookProvider{Provider: Provider{ProviderID: FacebookProviderID}, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+//NewGitHubProvider creates a new GitHub directory provider
+func NewGitHubProvider(clientID string, clientSecret string) *GitHubProvider {
+	return &GitHubProvider{Provider: Provider{ProviderID: GitHubProviderID}, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+//NewLinkedInProvider creates a new LinkedIn directory provider
+func NewLinkedInProvider(clientID string, clientSecret string) *LinkedInProvider {
+	return &LinkedInProvider{Provider: Provider{ProviderID: LinkedInProviderID}, ClientID: clientID, ClientSecret: clientSecret}
+}
+
+//NewLDAPProvider creates a new LDAP directory provider with the given agent configuration,
+//use ActiveDirectoryProviderID as providerID for Microsoft Active Directory
+func NewLDAPProvider(providerID string, config LDAPAgentConfig) *LDAPProvider {
+	return &LDAPProvider{Provider: Provider{ProviderID: providerID}, Agent: &LDAPAgent{Config: config}}
+}
+
+//NewSAMLProvider creates a new SAML directory provider, the signing certificate must be PEM encoded
+func NewSAMLProvider(ssoLoginURL string, ssoLogoutURL string, encodedX509SigningCert string) *SAMLProvider {
+	return &SAMLProvider{
+		Provider:                  Provider{ProviderID: SAMLProviderID},
+		SSOLoginURL:               ssoLoginURL,
+		SSOLogoutURL:              ssoLogoutURL,
+		EncodedX509SigningCert:    encodedX509SigningCert,
+		RequestSignatureAlgorithm: "RSA-SHA256",
+	}
+}
+
+//GetProvider loads the directory provider and returns it as its concrete type,
+//*GoogleProvider, *LDAPProvider, *SAMLProvider, etc. or *Provider for cloud directories
+//
+//See: http://docs.stormpath.com/rest/product-guide/#directory-provider
+func (dir *Directory) GetProvider(ctx context.Context) (DirectoryProvider, error) {
+	data := json.RawMessage{}
+
+	err := getClient(ctx).get(buildAbsoluteURL(dir.Href, "provider"), emptyPayload(), &data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalProvider(data)
+}
+
+//unmarshalProvider decodes a provider JSON document into the concrete type matching its providerId,
+//a provider link or an unknown provider is decoded as *Provider
+func unmarshalProvider(data []byte) (DirectoryProvider, error) {
+	p := &Provider{}
+
+	err := json.Unmarshal(data, p)
+	if err != nil {
+		return nil, err
+	}
+
+	var provider DirectoryProvider
+
+	switch p.ProviderID {
+	case GoogleProviderID:
+		provider = &GoogleProvider{}
+	case FacebookProviderID:
+		provider = &FacebookProvider{}
+	case GitHubProviderID:
+		provider = &GitHubProvider{}
+	case LinkedInProviderID:
+		provider = &LinkedInProvider{}
+	case LDAPProviderID, ActiveDirectoryProviderID:
+		provider = &LDAPProvider{}
+	case SAMLProviderID:
+		provider = &SAMLProvider{}
+	default:
+		return p, nil
+	}
+
+	err = json.Unmarshal(data, provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
@@ -18,6 +18,59 @@ var _ = Describe("Directory", func() {
 
 			Expect(string(jsonData)).To(Equal("{\"name\":\"name\"}"))
 		})
+
+		It("should marshal the directory provider configuration", func() {
+			directory := NewDirectoryWithProvider("name", NewFacebookProvider("id", "secret"))
+
+			jsonData, _ := json.Marshal(directory)
+
+			Expect(string(jsonData)).To(Equal("{\"name\":\"name\",\"provider\":{\"providerId\":\"facebook\",\"clientId\":\"id\",\"clientSecret\":\"secret\"}}"))
+		})
+
+		It("should unmarshal the directory provider into its concrete type", func() {
+			directory := &Directory{}
+
+			err := json.Unmarshal([]byte("{\"name\":\"name\",\"provider\":{\"providerId\":\"github\",\"clientId\":\"id\"}}"), directory)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(directory.Provider).To(BeAssignableToTypeOf(&GitHubProvider{}))
+			Expect(directory.Provider.(*GitHubProvider).ClientID).To(Equal("id"))
+		})
+
+		It("should unmarshal a provider link as a Provider", func() {
+			directory := &Directory{}
+
+			err := json.Unmarshal([]byte("{\"name\":\"name\",\"provider\":{\"href\":\"href\"}}"), directory)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(directory.Provider).To(BeAssignableToTypeOf(&Provider{}))
+		})
+	})
+
+	Describe("GetProvider", func() {
+		It("should return the provider of a cloud directory", func() {
+			directory := newTestDirectory()
+			tenant.CreateDirectory(ctx, directory)
+
+			provider, err := directory.GetProvider(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.GetProviderID()).To(Equal(StormpathProviderID))
+			directory.Delete(ctx)
+		})
+
+		It("should return the concrete provider of a social directory", func() {
+			directory := NewDirectoryWithProvider("directory-"+randomName(), NewGoogleProvider("id", "secret", "http://localhost:8080/callback"))
+			err := tenant.CreateDirectory(ctx, directory)
+			Expect(err).NotTo(HaveOccurred())
+
+			provider, err := directory.GetProvider(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(provider.(*GoogleProvider).ClientID).To(Equal("id"))
+			Expect(provider.(*GoogleProvider).RedirectURI).To(Equal("http://localhost:8080/callback"))
+			directory.Delete(ctx)
+		})
 	})
 
 	Describe("Delete", func() {
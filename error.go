@@ -31,7 +31,8 @@ func (client *Client) handleResponseError(resp *http.Response, err error) error
 		return err
 	}
 	//Check for Stormpath specific errors
-	if resp.StatusCode != 200 && resp.StatusCode != 204 && resp.StatusCode != 201 && resp.StatusCode != 302 {
+	//202 Accepted is returned by asynchronous requests such as the verification emails, without a body
+	if resp.StatusCode != 200 && resp.StatusCode != 202 && resp.StatusCode != 204 && resp.StatusCode != 201 && resp.StatusCode != 302 {
 		spError := &Error{}
 
 		err := json.NewDecoder(resp.Body).Decode(spError)
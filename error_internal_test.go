@@ -0,0 +1,22 @@
+package stormpath
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("handleResponseError", func() {
+	It("should accept the successful responses without a body", func() {
+		client := &Client{}
+
+		for _, status := range []int{http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent} {
+			resp := &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(""))}
+
+			Expect(client.handleResponseError(resp, nil)).To(BeNil())
+		}
+	})
+})
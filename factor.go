@@ -0,0 +1,182 @@
+package stormpath
+
+import "golang.org/x/net/context"
+
+//Factor types
+const (
+	SMSFactorType                 = "SMS"
+	GoogleAuthenticatorFactorType = "google-authenticator"
+)
+
+//Challenge statuses
+const (
+	ChallengeCreated     = "CREATED"
+	ChallengeWaiting     = "WAITING_FOR_VALIDATION"
+	ChallengeSuccess     = "SUCCESS"
+	ChallengeFailed      = "FAILED"
+	ChallengeDenied      = "DENIED"
+	ChallengeCancelled   = "CANCELLED"
+	ChallengeExpired     = "EXPIRED"
+	ChallengeError       = "ERROR"
+	ChallengeUndelivered = "UNDELIVERED"
+	ChallengeDelivered   = "DELIVERED"
+)
+
+//Factor represents an account second authentication factor, either an SMS factor bound to a phone
+//or a Google Authenticator (TOTP) factor
+//
+//See: http://docs.stormpath.com/rest/product-guide/#multi-factor-authentication
+type Factor struct {
+	resource
+	Type                string      `json:"type"`
+	Status              string      `json:"status,omitempty"`
+	VerificationStatus  string      `json:"verificationStatus,omitempty"`
+	AccountName         string      `json:"accountName,omitempty"`
+	Issuer              string      `json:"issuer,omitempty"`
+	Secret              string      `json:"secret,omitempty"`
+	KeyURI              string      `json:"keyUri,omitempty"`
+	Base64QRImage       string      `json:"base64QRImage,omitempty"`
+	Phone               *Phone      `json:"phone,omitempty"`
+	Account             *Account    `json:"account,omitempty"`
+	Challenges          *Challenges `json:"challenges,omitempty"`
+	MostRecentChallenge *Challenge  `json:"mostRecentChallenge,omitempty"`
+}
+
+//Factors represents a paged result of account factors
+type Factors struct {
+	collectionResource
+	Items []Factor `json:"items"`
+}
+
+//Phone represents an account phone, use by SMS factors
+type Phone struct {
+	resource
+	Number             string   `json:"number,omitempty"`
+	Name               string   `json:"name,omitempty"`
+	Description        string   `json:"description,omitempty"`
+	Status             string   `json:"status,omitempty"`
+	VerificationStatus string   `json:"verificationStatus,omitempty"`
+	Account            *Account `json:"account,omitempty"`
+}
+
+//Phones represents a paged result of account phones
+type Phones struct {
+	collectionResource
+	Items []Phone `json:"items"`
+}
+
+//Challenge represents a factor challenge, the account proves the possession of the factor by
+//submitting the code sent by SMS or generated by its authenticator app
+type Challenge struct {
+	resource
+	Message string   `json:"message,omitempty"`
+	Code    string   `json:"code,omitempty"`
+	Status  string   `json:"status,omitempty"`
+	Factor  *Factor  `json:"factor,omitempty"`
+	Account *Account `json:"account,omitempty"`
+}
+
+//Challenges represents a paged result of factor challenges
+type Challenges struct {
+	collectionResource
+	Items []Challenge `json:"items"`
+}
+
+//NewSMSFactor creates a new SMS factor for the given phone number (E.164 format)
+func NewSMSFactor(phoneNumber string) *Factor {
+	return &Factor{Type: SMSFactorType, Phone: &Phone{Number: phoneNumber}}
+}
+
+//NewGoogleAuthenticatorFactor creates a new Google Authenticator factor, the issuer and account name
+//are the labels displayed by the authenticator app
+func NewGoogleAuthenticatorFactor(issuer string, accountName string) *Factor {
+	return &Factor{Type: GoogleAuthenticatorFactorType, Issuer: issuer, AccountName: accountName}
+}
+
+//CreateFactor creates a new factor for the given account, for Google Authenticator factors the response
+//holds the secret and the QR code to setup the authenticator app
+//
+//See: http://docs.stormpath.com/rest/product-guide/#multi-factor-authentication
+func (account *Account) CreateFactor(ctx context.Context, factor *Factor) error {
+	return getClient(ctx).post(buildAbsoluteURL(account.Href, "factors"), factor, factor)
+}
+
+//GetFactors returns a paged result of the factors of the given account
+func (account *Account) GetFactors(ctx context.Context, criteria Criteria) (*Factors, error) {
+	factors := &Factors{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(account.Href, "factors", criteria.ToQueryString()),
+		emptyPayload(),
+		factors,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return factors, nil
+}
+
+//GetPhones returns a paged result of the phones of the given account
+func (account *Account) GetPhones(ctx context.Context, criteria Criteria) (*Phones, error) {
+	phones := &Phones{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(account.Href, "phones", criteria.ToQueryString()),
+		emptyPayload(),
+		phones,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return phones, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (factor *Factor) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(factor.Href, emptyPayload(), factor)
+}
+
+//CreateChallenge creates a new challenge for the factor. For SMS factors message is the SMS text and
+//must contain the ${code} placeholder, an empty message uses the Stormpath default. For Google Authenticator
+//factors message is ignored and the challenge must be submitted with the code from the authenticator app.
+func (factor *Factor) CreateChallenge(ctx context.Context, message string) (*Challenge, error) {
+	challenge := &Challenge{}
+	if factor.Type == SMSFactorType {
+		challenge.Message = message
+	}
+
+	err := getClient(ctx).post(buildAbsoluteURL(factor.Href, "challenges"), challenge, challenge)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (challenge *Challenge) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(challenge.Href, emptyPayload(), challenge)
+}
+
+//Submit validates the challenge with the given code, the challenge status is updated with the outcome
+//and can be checked with IsSuccess
+func (challenge *Challenge) Submit(ctx context.Context, code string) error {
+	return getClient(ctx).post(challenge.Href, map[string]string{"code": code}, challenge)
+}
+
+//IsSuccess returns true if the challenge was validated with a correct code
+func (challenge *Challenge) IsSuccess() bool {
+	return challenge.Status == ChallengeSuccess
+}
+
+//IsPending returns true if the challenge is still waiting for a code to be submitted
+func (challenge *Challenge) IsPending() bool {
+	return challenge.Status == ChallengeCreated ||
+		challenge.Status == ChallengeWaiting ||
+		challenge.Status == ChallengeDelivered
+}
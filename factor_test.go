@@ -0,0 +1,89 @@
+package stormpath_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Factor", func() {
+	Describe("JSON", func() {
+		It("should marshall a minimum SMS factor JSON", func() {
+			factor := NewSMSFactor("+15555555555")
+
+			jsonData, _ := json.Marshal(factor)
+
+			Expect(string(jsonData)).To(Equal("{\"type\":\"SMS\",\"phone\":{\"number\":\"+15555555555\"}}"))
+		})
+
+		It("should marshall a minimum Google Authenticator factor JSON", func() {
+			factor := NewGoogleAuthenticatorFactor("issuer", "name")
+
+			jsonData, _ := json.Marshal(factor)
+
+			Expect(string(jsonData)).To(Equal("{\"type\":\"google-authenticator\",\"accountName\":\"name\",\"issuer\":\"issuer\"}"))
+		})
+	})
+
+	Describe("GenerateTOTPCode", func() {
+		//RFC 6238 SHA1 test vectors truncated to 6 digits
+		secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+		It("should generate the RFC 6238 codes", func() {
+			vectors := map[int64]string{
+				59:         "287082",
+				1111111109: "081804",
+				1111111111: "050471",
+				1234567890: "005924",
+				2000000000: "279037",
+			}
+			for t, expected := range vectors {
+				code, err := GenerateTOTPCode(secret, time.Unix(t, 0))
+
+				Expect(err).NotTo(HaveOccurred())
+				Expect(code).To(Equal(expected))
+			}
+		})
+
+		It("should return an error if the secret is not base32 encoded", func() {
+			_, err := GenerateTOTPCode("not base32!", time.Now())
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Google Authenticator challenge", func() {
+		It("should validate a challenge with the TOTP code", func() {
+			account := registerTestAccount(ctx)
+			factor := NewGoogleAuthenticatorFactor("stormpath-sdk-go", account.Email)
+
+			err := account.CreateFactor(ctx, factor)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(factor.Secret).NotTo(BeEmpty())
+
+			challenge, err := factor.CreateChallenge(ctx, "")
+			Expect(err).NotTo(HaveOccurred())
+
+			code, _ := GenerateTOTPCode(factor.Secret, time.Now())
+			err = challenge.Submit(ctx, code)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(challenge.IsSuccess()).To(BeTrue())
+		})
+
+		It("should fail a challenge with a wrong code", func() {
+			account := registerTestAccount(ctx)
+			factor := NewGoogleAuthenticatorFactor("stormpath-sdk-go", account.Email)
+			account.CreateFactor(ctx, factor)
+
+			challenge, _ := factor.CreateChallenge(ctx, "")
+			challenge.Submit(ctx, "000000")
+
+			Expect(challenge.IsSuccess()).To(BeFalse())
+			Expect(challenge.IsPending()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,328 @@
+package stormpath
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/nu7hatch/gouuid"
+	"golang.org/x/net/context"
+)
+
+//ID Site errors
+var (
+	ErrInvalidCallbackURI      = errors.New("ID Site callback URI must be an absolute URL")
+	ErrUnauthorizedCallbackURI = errors.New("ID Site callback URI is not an application authorized callback URI")
+	ErrInvalidIDSiteToken      = errors.New("ID Site jwtResponse is missing, malformed or its signature is invalid")
+	ErrIDSiteTokenExpired      = errors.New("ID Site jwtResponse has expired")
+	ErrInvalidIDSiteAudience   = errors.New("ID Site jwtResponse aud doesn't match the API key ID")
+	ErrIDSiteTokenReplayed     = errors.New("ID Site jwtResponse has already been used")
+)
+
+//DefaultIDSiteClockSkew is the tolerance applied to the ID Site response expiration when
+//IDSiteCallbackOptions.ClockSkew is not set
+const DefaultIDSiteClockSkew = 1 * time.Minute
+
+//defaultIDSiteNonceStore is used when IDSiteCallbackOptions.NonceStore is not set
+var defaultIDSiteNonceStore = NewInMemoryNonceStore(1 * time.Hour)
+
+//InvalidIDSiteClaimError is returned when a required ID Site response claim is missing or has the wrong type
+type InvalidIDSiteClaimError struct {
+	Claim string
+}
+
+func (e InvalidIDSiteClaimError) Error() string {
+	return "ID Site jwtResponse claim " + e.Claim + " is missing or invalid"
+}
+
+//IDSiteOptions holds the settings of an ID Site redirect.
+//
+//CallbackURI is required and must be absolute, Path defaults to "/" and Logout sends the user to the ID Site
+//logout endpoint. OrganizationNameKey, ShowOrganizationField and UseSubdomain control the ID Site organization
+//support, SPToken is the token of an already started ID Site flow and CustomClaims are added as is
+//to the jwtRequest, they never override the claims set by the SDK.
+//
+//See: http://docs.stormpath.com/guides/using-id-site/
+type IDSiteOptions struct {
+	CallbackURI           string
+	Path                  string
+	State                 string
+	Logout                bool
+	OrganizationNameKey   string
+	ShowOrganizationField bool
+	UseSubdomain          bool
+	SPToken               string
+	CustomClaims          map[string]interface{}
+}
+
+//IDSiteCallbackOptions configures the validation of the ID Site and SAML callbacks. NonceStore rejects
+//the replayed responses, it defaults to a process wide in memory store which other instances don't see, so a
+//MemcacheNonceStore must be used when running several instances. ClockSkew is tolerated on the response expiration,
+//it defaults to DefaultIDSiteClockSkew.
+type IDSiteCallbackOptions struct {
+	NonceStore NonceStore
+	ClockSkew  time.Duration
+}
+
+//IDSiteStatus is the status of an ID Site or SAML callback
+type IDSiteStatus string
+
+//ID Site callback statuses
+const (
+	IDSiteAuthenticated IDSiteStatus = "AUTHENTICATED"
+	IDSiteRegistered    IDSiteStatus = "REGISTERED"
+	IDSiteLogout        IDSiteStatus = "LOGOUT"
+)
+
+//IDSiteCallbackResult holds the ID Site callback parsed JWT token information + the acccount if one was given,
+//IsNew is true when the account was created during the ID Site flow (e.g. the first social login)
+//and Claims holds all the jwtResponse claims
+type IDSiteCallbackResult struct {
+	Account          *Account
+	State            string
+	IsNew            bool
+	Status           IDSiteStatus
+	OrganizationHref string
+	Claims           map[string]interface{}
+}
+
+//IDSiteOptionsFromMap converts the legacy map based ID Site options (callbackURI, path, state and logout="true")
+//into IDSiteOptions
+func IDSiteOptionsFromMap(options map[string]string) IDSiteOptions {
+	return IDSiteOptions{
+		CallbackURI: options["callbackURI"],
+		Path:        options["path"],
+		State:       options["state"],
+		Logout:      options["logout"] == "true",
+	}
+}
+
+//CreateIDSiteURL creates the IDSite URL for the application from map based options,
+//see CreateIDSiteURLWithOptions and IDSiteOptionsFromMap
+func (app *Application) CreateIDSiteURL(ctx context.Context, options map[string]string) (string, error) {
+	return app.CreateIDSiteURLWithOptions(ctx, IDSiteOptionsFromMap(options))
+}
+
+//CreateIDSiteURLWithOptions creates the IDSite URL for the application. The callback URI must be absolute and
+//match one of the application authorized callback URIs, where "*" matches within a host label or path segment.
+func (app *Application) CreateIDSiteURLWithOptions(ctx context.Context, options IDSiteOptions) (string, error) {
+	err := app.validateCallbackURI(options.CallbackURI)
+	if err != nil {
+		return "", err
+	}
+
+	client := getClient(ctx)
+
+	token := jwt.New(jwt.SigningMethodHS256)
+
+	nonce, _ := uuid.NewV4()
+
+	path := options.Path
+	if path == "" {
+		path = "/"
+	}
+
+	for name, value := range options.CustomClaims {
+		token.Claims[name] = value
+	}
+
+	token.Claims["jti"] = nonce.String()
+	token.Claims["iat"] = time.Now().Unix()
+	token.Claims["iss"] = client.Credentials.ID
+	token.Claims["sub"] = app.Href
+	token.Claims["state"] = options.State
+	token.Claims["path"] = path
+	token.Claims["cb_uri"] = options.CallbackURI
+	if options.OrganizationNameKey != "" {
+		token.Claims["onk"] = options.OrganizationNameKey
+	}
+	if options.ShowOrganizationField {
+		token.Claims["sof"] = true
+	}
+	if options.UseSubdomain {
+		token.Claims["usd"] = true
+	}
+	if options.SPToken != "" {
+		token.Claims["sp_token"] = options.SPToken
+	}
+
+	tokenString, err := token.SignedString([]byte(client.Credentials.Secret))
+	if err != nil {
+		return "", err
+	}
+
+	p, _ := url.Parse(app.Href)
+	ssoURL := p.Scheme + "://" + p.Host + "/sso"
+
+	if options.Logout {
+		ssoURL = ssoURL + "/logout" + "?jwtRequest=" + tokenString
+	} else {
+		ssoURL = ssoURL + "?jwtRequest=" + tokenString
+	}
+
+	return ssoURL, nil
+}
+
+//validateCallbackURI checks that the callback URI is absolute and matches one of the application authorized
+//callback URIs, every callback URI is rejected when the application doesn't have any
+func (app *Application) validateCallbackURI(callbackURI string) error {
+	u, err := url.Parse(callbackURI)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return ErrInvalidCallbackURI
+	}
+
+	for _, authorizedURI := range app.AuthorizedCallbackURIs {
+		if matchCallbackURI(authorizedURI, u) {
+			return nil
+		}
+	}
+
+	return ErrUnauthorizedCallbackURI
+}
+
+//matchCallbackURI matches a callback URI against an authorized callback URI pattern. The scheme, host and path
+//are compared separately, the query is ignored, and "*" only matches within a single host label or path segment
+//so "https://*.example.com/callback" can't match "https://evil.com/x.example.com/callback".
+func matchCallbackURI(pattern string, callbackURI *url.URL) bool {
+	p, err := url.Parse(pattern)
+	if err != nil || !p.IsAbs() || p.Host == "" {
+		return false
+	}
+
+	return strings.EqualFold(p.Scheme, callbackURI.Scheme) &&
+		matchWildcard(strings.ToLower(p.Host), strings.ToLower(callbackURI.Host), "[^./]*") &&
+		matchWildcard(callbackPath(p), callbackPath(callbackURI), "[^/]*")
+}
+
+//matchWildcard matches a value against a pattern where "*" is replaced by the given regular expression
+func matchWildcard(pattern string, value string, wildcard string) bool {
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	matched, _ := regexp.MatchString("^"+strings.Join(parts, wildcard)+"$", value)
+	return matched
+}
+
+func callbackPath(u *url.URL) string {
+	if u.Path == "" {
+		return "/"
+	}
+	return u.Path
+}
+
+//HandleIDSiteCallback handles the URL from an ID Site callback it parses the JWT token
+//validates it and return an IDSiteCallbackResult with the token info + the Account if the sub was given.
+//
+//The token must be signed with HS256 using the API key secret, its aud must be the API key ID, it must not be
+//expired (the clock skew is tolerated) and its nonce (irt) must not have been used before, see IDSiteCallbackOptions.
+func (app *Application) HandleIDSiteCallback(ctx context.Context, URL string, options ...IDSiteCallbackOptions) (*IDSiteCallbackResult, error) {
+	result := &IDSiteCallbackResult{}
+
+	nonceStore := NonceStore(defaultIDSiteNonceStore)
+	clockSkew := DefaultIDSiteClockSkew
+	if len(options) > 0 {
+		if options[0].NonceStore != nil {
+			nonceStore = options[0].NonceStore
+		}
+		if options[0].ClockSkew > 0 {
+			clockSkew = options[0].ClockSkew
+		}
+	}
+
+	cbURL, err := url.Parse(URL)
+	if err != nil {
+		return nil, err
+	}
+
+	jwtResponse := cbURL.Query().Get("jwtResponse")
+	if jwtResponse == "" {
+		return nil, ErrInvalidIDSiteToken
+	}
+
+	client := getClient(ctx)
+
+	token, err := jwt.Parse(jwtResponse, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, ErrInvalidIDSiteToken
+		}
+		return []byte(client.Credentials.Secret), nil
+	})
+	if err != nil {
+		//The expiration is checked below with the clock skew tolerance
+		vErr, ok := err.(*jwt.ValidationError)
+		if !ok || vErr.Errors&^jwt.ValidationErrorExpired != 0 {
+			return nil, ErrInvalidIDSiteToken
+		}
+	}
+
+	aud, ok := token.Claims["aud"].(string)
+	if !ok {
+		return nil, InvalidIDSiteClaimError{"aud"}
+	}
+	if aud != client.Credentials.ID {
+		return nil, ErrInvalidIDSiteAudience
+	}
+
+	exp, ok := token.Claims["exp"].(float64)
+	if !ok {
+		return nil, InvalidIDSiteClaimError{"exp"}
+	}
+	if time.Now().Add(-clockSkew).Unix() > int64(exp) {
+		return nil, ErrIDSiteTokenExpired
+	}
+
+	if iss, ok := token.Claims["iss"].(string); !ok || iss == "" {
+		return nil, InvalidIDSiteClaimError{"iss"}
+	}
+
+	status, ok := token.Claims["status"].(string)
+	if !ok {
+		return nil, InvalidIDSiteClaimError{"status"}
+	}
+	switch IDSiteStatus(status) {
+	case IDSiteAuthenticated, IDSiteRegistered, IDSiteLogout:
+	default:
+		return nil, InvalidIDSiteClaimError{"status"}
+	}
+
+	nonce, ok := token.Claims["irt"].(string)
+	if !ok || nonce == "" {
+		return nil, InvalidIDSiteClaimError{"irt"}
+	}
+	if !nonceStore.UseNonce(ctx, nonce) {
+		return nil, ErrIDSiteTokenReplayed
+	}
+
+	if sub, ok := token.Claims["sub"]; ok && sub != nil {
+		accountHref, ok := sub.(string)
+		if !ok {
+			return nil, InvalidIDSiteClaimError{"sub"}
+		}
+		account, err := GetAccount(ctx, accountHref, MakeAccountCriteria())
+		if err != nil {
+			return nil, err
+		}
+		result.Account = account
+	}
+	if state, ok := token.Claims["state"]; ok && state != nil {
+		result.State, ok = state.(string)
+		if !ok {
+			return nil, InvalidIDSiteClaimError{"state"}
+		}
+	}
+	if isNew, ok := token.Claims["isNewSub"].(bool); ok {
+		result.IsNew = isNew
+	}
+	if orgHref, ok := token.Claims["org_href"].(string); ok {
+		result.OrganizationHref = orgHref
+	}
+	result.Status = IDSiteStatus(status)
+	result.Claims = token.Claims
+
+	return result, nil
+}
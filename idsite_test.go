@@ -0,0 +1,310 @@
+package stormpath_test
+
+import (
+	"encoding/base64"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+func parseIDSiteURL(idSiteURL string) (*url.URL, *jwt.Token) {
+	u, _ := url.Parse(idSiteURL)
+
+	token, _ := jwt.Parse(u.Query().Get("jwtRequest"), func(token *jwt.Token) (interface{}, error) {
+		return []byte(cred.Secret), nil
+	})
+
+	return u, token
+}
+
+func newIDSiteCallbackURL(claims map[string]interface{}) string {
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Claims["aud"] = cred.ID
+	token.Claims["iss"] = "https://id.example.com"
+	token.Claims["exp"] = time.Now().Add(time.Minute).Unix()
+	token.Claims["irt"] = randomName()
+	token.Claims["status"] = "AUTHENTICATED"
+	for name, value := range claims {
+		if value == nil {
+			delete(token.Claims, name)
+		} else {
+			token.Claims[name] = value
+		}
+	}
+	jwtResponse, _ := token.SignedString([]byte(cred.Secret))
+
+	return "http://localhost:8080/callback?jwtResponse=" + jwtResponse
+}
+
+//concurrentNonceUses uses the same nonce from several goroutines at once and returns how many uses were accepted
+func concurrentNonceUses(store NonceStore) int {
+	var accepted int32
+	var wg sync.WaitGroup
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.UseNonce(ctx, "nonce") {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return int(accepted)
+}
+
+var _ = Describe("IDSite", func() {
+	Describe("IDSiteOptionsFromMap", func() {
+		It("should convert the legacy map options", func() {
+			options := IDSiteOptionsFromMap(map[string]string{
+				"callbackURI": "http://localhost:8080",
+				"path":        "/#/register",
+				"state":       "state",
+				"logout":      "true",
+			})
+
+			Expect(options).To(Equal(IDSiteOptions{CallbackURI: "http://localhost:8080", Path: "/#/register", State: "state", Logout: true}))
+		})
+	})
+
+	Describe("CreateIDSiteURL", func() {
+		It("should not mutate the given options", func() {
+			options := map[string]string{"callbackURI": "http://localhost:8080"}
+
+			app.CreateIDSiteURL(ctx, options)
+
+			Expect(options).To(HaveLen(1))
+		})
+	})
+
+	Describe("CreateIDSiteURLWithOptions", func() {
+		It("should set the organization, subdomain, sp_token and custom claims", func() {
+			idSiteURL, err := app.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{
+				CallbackURI:           "http://localhost:8080",
+				OrganizationNameKey:   "org",
+				ShowOrganizationField: true,
+				UseSubdomain:          true,
+				SPToken:               "sptoken",
+				CustomClaims:          map[string]interface{}{"custom": "value", "sub": "ignored"},
+			})
+
+			u, token := parseIDSiteURL(idSiteURL)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.Path).To(Equal("/sso"))
+			Expect(token.Valid).To(BeTrue())
+			Expect(token.Claims["onk"]).To(Equal("org"))
+			Expect(token.Claims["sof"]).To(BeTrue())
+			Expect(token.Claims["usd"]).To(BeTrue())
+			Expect(token.Claims["sp_token"]).To(Equal("sptoken"))
+			Expect(token.Claims["custom"]).To(Equal("value"))
+			Expect(token.Claims["sub"]).To(Equal(app.Href))
+		})
+
+		It("should return an error if the callback URI is not absolute", func() {
+			idSiteURL, err := app.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "/callback"})
+
+			Expect(err).To(Equal(ErrInvalidCallbackURI))
+			Expect(idSiteURL).To(BeEmpty())
+		})
+
+		It("should only accept the application authorized callback URIs", func() {
+			a := *app
+			a.AuthorizedCallbackURIs = []string{"https://example.com/callback", "https://*.example.com/callback"}
+
+			_, err := a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "https://example.com/callback"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "https://tenant.example.com/callback"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "https://evil.com/callback"})
+			Expect(err).To(Equal(ErrUnauthorizedCallbackURI))
+		})
+
+		It("should only match a wildcard within a single host label or path segment", func() {
+			a := *app
+			a.AuthorizedCallbackURIs = []string{"https://*.example.com/callback", "https://example.com/*/callback"}
+
+			for _, callbackURI := range []string{
+				"https://evil.com/x.example.com/callback",
+				"https://evil.com?.example.com/callback",
+				"https://a.b.example.com/callback",
+				"https://tenant.example.com.evil.com/callback",
+				"https://user@evil.com/.example.com/callback",
+				"http://tenant.example.com/callback",
+				"https://tenant.example.com/callback/other",
+				"https://example.com/a/b/callback",
+			} {
+				_, err := a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: callbackURI})
+				Expect(err).To(Equal(ErrUnauthorizedCallbackURI), callbackURI)
+			}
+
+			_, err := a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "https://example.com/tenant/callback?x=y"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should reject every callback URI if the application has no authorized callback URIs", func() {
+			a := *app
+			a.AuthorizedCallbackURIs = nil
+
+			_, err := a.CreateIDSiteURLWithOptions(ctx, IDSiteOptions{CallbackURI: "http://localhost:8080"})
+
+			Expect(err).To(Equal(ErrUnauthorizedCallbackURI))
+		})
+	})
+
+	Describe("HandleIDSiteCallback", func() {
+		It("should return the callback status and state", func() {
+			result, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"state": "state"}))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Account).To(BeNil())
+			Expect(result.State).To(Equal("state"))
+			Expect(result.Status).To(Equal(IDSiteAuthenticated))
+			Expect(result.IsNew).To(BeFalse())
+		})
+
+		It("should return the registered status, the new account flag and the organization", func() {
+			result, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{
+				"status":   "REGISTERED",
+				"isNewSub": true,
+				"org_href": "https://api.stormpath.com/v1/organizations/xxxx",
+			}))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Status).To(Equal(IDSiteRegistered))
+			Expect(result.IsNew).To(BeTrue())
+			Expect(result.OrganizationHref).To(Equal("https://api.stormpath.com/v1/organizations/xxxx"))
+			Expect(result.Claims["org_href"]).To(Equal("https://api.stormpath.com/v1/organizations/xxxx"))
+		})
+
+		It("should return the logout status", func() {
+			result, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"status": "LOGOUT"}))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Status).To(Equal(IDSiteLogout))
+		})
+
+		It("should reject an unknown status", func() {
+			_, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"status": "UNKNOWN"}))
+
+			Expect(err).To(Equal(InvalidIDSiteClaimError{"status"}))
+		})
+
+		It("should reject a replayed response", func() {
+			callbackURL := newIDSiteCallbackURL(nil)
+
+			_, err := app.HandleIDSiteCallback(ctx, callbackURL)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.HandleIDSiteCallback(ctx, callbackURL)
+			Expect(err).To(Equal(ErrIDSiteTokenReplayed))
+		})
+
+		It("should reject a response without jwtResponse", func() {
+			_, err := app.HandleIDSiteCallback(ctx, "http://localhost:8080/callback")
+
+			Expect(err).To(Equal(ErrInvalidIDSiteToken))
+		})
+
+		It("should reject a response that is not signed with HS256", func() {
+			header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+			claims := base64.RawURLEncoding.EncodeToString([]byte(`{"aud":"` + cred.ID + `","status":"AUTHENTICATED"}`))
+
+			_, err := app.HandleIDSiteCallback(ctx, "http://localhost:8080/callback?jwtResponse="+header+"."+claims+".")
+
+			Expect(err).To(Equal(ErrInvalidIDSiteToken))
+		})
+
+		It("should reject a response with an invalid signature", func() {
+			token := jwt.New(jwt.SigningMethodHS256)
+			token.Claims["aud"] = cred.ID
+			jwtResponse, _ := token.SignedString([]byte("wrong secret"))
+
+			_, err := app.HandleIDSiteCallback(ctx, "http://localhost:8080/callback?jwtResponse="+jwtResponse)
+
+			Expect(err).To(Equal(ErrInvalidIDSiteToken))
+		})
+
+		It("should reject a response for another API key", func() {
+			_, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"aud": "other"}))
+
+			Expect(err).To(Equal(ErrInvalidIDSiteAudience))
+		})
+
+		It("should return a claim error if a required claim is missing", func() {
+			for _, claim := range []string{"aud", "exp", "iss", "status", "irt"} {
+				_, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{claim: nil}))
+
+				Expect(err).To(Equal(InvalidIDSiteClaimError{claim}))
+			}
+		})
+
+		It("should reject a response replayed against the given NonceStore", func() {
+			options := IDSiteCallbackOptions{NonceStore: NewInMemoryNonceStore(time.Hour)}
+			callbackURL := newIDSiteCallbackURL(nil)
+
+			_, err := app.HandleIDSiteCallback(ctx, callbackURL, options)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.HandleIDSiteCallback(ctx, callbackURL, options)
+			Expect(err).To(Equal(ErrIDSiteTokenReplayed))
+		})
+
+		It("should tolerate the given clock skew on the expiration", func() {
+			options := IDSiteCallbackOptions{ClockSkew: 5 * time.Minute}
+
+			_, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"exp": time.Now().Add(-2 * time.Minute).Unix()}), options)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should tolerate the clock skew on the expiration", func() {
+			_, err := app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"exp": time.Now().Add(-30 * time.Second).Unix()}))
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.HandleIDSiteCallback(ctx, newIDSiteCallbackURL(map[string]interface{}{"exp": time.Now().Add(-2 * time.Minute).Unix()}))
+			Expect(err).To(Equal(ErrIDSiteTokenExpired))
+		})
+	})
+
+	Describe("InMemoryNonceStore", func() {
+		It("should only accept a nonce once", func() {
+			store := NewInMemoryNonceStore(time.Hour)
+
+			Expect(store.UseNonce(ctx, "nonce")).To(BeTrue())
+			Expect(store.UseNonce(ctx, "nonce")).To(BeFalse())
+		})
+
+		It("should forget the nonces after the TTL", func() {
+			store := NewInMemoryNonceStore(10 * time.Millisecond)
+
+			Expect(store.UseNonce(ctx, "nonce")).To(BeTrue())
+
+			time.Sleep(20 * time.Millisecond)
+			Expect(store.UseNonce(ctx, "nonce")).To(BeTrue())
+		})
+
+		It("should only accept one of concurrent uses of the same nonce", func() {
+			Expect(concurrentNonceUses(NewInMemoryNonceStore(time.Hour))).To(Equal(1))
+		})
+	})
+
+	Describe("MemcacheNonceStore", func() {
+		It("should only accept one of concurrent uses of the same nonce", func() {
+			store := MemcacheNonceStore{TTL: time.Hour}
+
+			Expect(concurrentNonceUses(store)).To(Equal(1))
+			Expect(store.UseNonce(ctx, "nonce")).To(BeFalse())
+		})
+	})
+})
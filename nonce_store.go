@@ -0,0 +1,66 @@
+package stormpath
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/appengine/memcache"
+)
+
+//NonceStore keeps track of the nonces already used, it is used to reject replayed ID Site and SAML responses
+type NonceStore interface {
+	//UseNonce records the nonce as used and returns true, or returns false if it was already used.
+	//The check and the update must be atomic so concurrent replays of the same nonce can't both succeed.
+	UseNonce(ctx context.Context, nonce string) bool
+}
+
+//InMemoryNonceStore is a NonceStore that keeps the nonces in memory for the given TTL, every instance has its own
+//nonces so it is only suitable for single instance applications, use a MemcacheNonceStore otherwise
+type InMemoryNonceStore struct {
+	TTL    time.Duration
+	mutex  sync.Mutex
+	nonces map[string]time.Time
+}
+
+//NewInMemoryNonceStore creates a new InMemoryNonceStore which remembers the nonces for the given TTL
+func NewInMemoryNonceStore(ttl time.Duration) *InMemoryNonceStore {
+	return &InMemoryNonceStore{TTL: ttl, nonces: make(map[string]time.Time)}
+}
+
+//UseNonce records the nonce as used unless it was already used and hasn't expired yet,
+//expired nonces are removed at the same time
+func (store *InMemoryNonceStore) UseNonce(ctx context.Context, nonce string) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	for n, expiresAt := range store.nonces {
+		if !now.Before(expiresAt) {
+			delete(store.nonces, n)
+		}
+	}
+
+	if _, ok := store.nonces[nonce]; ok {
+		return false
+	}
+	store.nonces[nonce] = now.Add(store.TTL)
+	return true
+}
+
+//MemcacheNonceStore is a NonceStore backed by the App Engine memcache, which is shared by all the instances of the
+//application, the nonces are kept for the given TTL. Memcache can evict a nonce before its TTL under memory pressure,
+//so the TTL should be kept close to the ID Site response lifetime.
+type MemcacheNonceStore struct {
+	TTL time.Duration
+}
+
+//UseNonce adds the nonce to memcache, the add fails if the nonce is already there. The nonce is rejected as well
+//when memcache can't be reached.
+func (store MemcacheNonceStore) UseNonce(ctx context.Context, nonce string) bool {
+	return memcache.Add(ctx, &memcache.Item{Key: nonceCacheKey(nonce), Value: []byte{}, Expiration: store.TTL}) == nil
+}
+
+func nonceCacheKey(nonce string) string {
+	return "stormpath-nonce#" + nonce
+}
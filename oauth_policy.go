@@ -0,0 +1,158 @@
+package stormpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+//iso8601DurationPattern matches the ISO-8601 durations used by Stormpath, years and months are not supported
+//since they don't map to a fixed time.Duration
+var iso8601DurationPattern = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+//OAuthPolicy represents an application OAuth policy object, it holds the time to live of the tokens
+//issued by the application OAuth token endpoint
+//
+//See: http://docs.stormpath.com/guides/token-management/#configuring-token-based-authentication
+type OAuthPolicy struct {
+	resource
+	AccessTokenTTL  time.Duration `json:"-"`
+	RefreshTokenTTL time.Duration `json:"-"`
+	TokenEndpoint   *resource     `json:"tokenEndpoint,omitempty"`
+	Application     *Application  `json:"application,omitempty"`
+	Tenant          *Tenant       `json:"tenant,omitempty"`
+}
+
+//rawOAuthPolicy is an alias of OAuthPolicy without the custom JSON marshaling
+type rawOAuthPolicy OAuthPolicy
+
+type oAuthPolicyJSON struct {
+	rawOAuthPolicy
+	AccessTokenTTL  string `json:"accessTokenTtl,omitempty"`
+	RefreshTokenTTL string `json:"refreshTokenTtl,omitempty"`
+}
+
+//MarshalJSON implements json.Marshaler, the TTLs are serialized as ISO-8601 durations
+func (policy OAuthPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(oAuthPolicyJSON{
+		rawOAuthPolicy:  rawOAuthPolicy(policy),
+		AccessTokenTTL:  formatISO8601Duration(policy.AccessTokenTTL),
+		RefreshTokenTTL: formatISO8601Duration(policy.RefreshTokenTTL),
+	})
+}
+
+//UnmarshalJSON implements json.Unmarshaler, the TTLs are parsed from ISO-8601 durations
+func (policy *OAuthPolicy) UnmarshalJSON(data []byte) error {
+	p := oAuthPolicyJSON{}
+
+	err := json.Unmarshal(data, &p)
+	if err != nil {
+		return err
+	}
+
+	*policy = OAuthPolicy(p.rawOAuthPolicy)
+
+	policy.AccessTokenTTL, err = parseISO8601Duration(p.AccessTokenTTL)
+	if err != nil {
+		return err
+	}
+
+	policy.RefreshTokenTTL, err = parseISO8601Duration(p.RefreshTokenTTL)
+	return err
+}
+
+//GetOAuthPolicy loads the application OAuth policy
+//
+//See: http://docs.stormpath.com/guides/token-management/#configuring-token-based-authentication
+func (app *Application) GetOAuthPolicy(ctx context.Context) (*OAuthPolicy, error) {
+	policy := &OAuthPolicy{}
+
+	err := getClient(ctx).get(app.OAuthPolicy.Href, emptyPayload(), policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (policy *OAuthPolicy) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(policy.Href, emptyPayload(), policy)
+}
+
+//Update updates the given resource, by doing a POST to the resource Href
+func (policy *OAuthPolicy) Update(ctx context.Context) error {
+	return getClient(ctx).post(policy.Href, policy, policy)
+}
+
+//parseISO8601Duration parses an ISO-8601 duration like PT1H or P60D, an empty string is a zero duration
+func parseISO8601Duration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	matches := iso8601DurationPattern.FindStringSubmatch(value)
+	if matches == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return 0, fmt.Errorf("invalid or unsupported ISO-8601 duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var duration time.Duration
+	for i, unit := range units {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(matches[i+1], 64)
+		if err != nil {
+			return 0, err
+		}
+		duration += time.Duration(n * float64(unit))
+	}
+
+	return duration, nil
+}
+
+//formatISO8601Duration formats a duration as an ISO-8601 duration, a zero duration is an empty string
+func formatISO8601Duration(duration time.Duration) string {
+	if duration <= 0 {
+		return ""
+	}
+
+	buffer := bytes.NewBufferString("P")
+
+	days := duration / (24 * time.Hour)
+	duration -= days * 24 * time.Hour
+	if days > 0 {
+		fmt.Fprintf(buffer, "%dD", days)
+	}
+
+	if duration > 0 {
+		buffer.WriteString("T")
+
+		hours := duration / time.Hour
+		duration -= hours * time.Hour
+		if hours > 0 {
+			fmt.Fprintf(buffer, "%dH", hours)
+		}
+
+		minutes := duration / time.Minute
+		duration -= minutes * time.Minute
+		if minutes > 0 {
+			fmt.Fprintf(buffer, "%dM", minutes)
+		}
+
+		if duration > 0 {
+			buffer.WriteString(strconv.FormatFloat(duration.Seconds(), 'f', -1, 64) + "S")
+		}
+	}
+
+	return buffer.String()
+}
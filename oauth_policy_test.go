@@ -0,0 +1,76 @@
+package stormpath_test
+
+import (
+	"encoding/json"
+	"time"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OAuthPolicy", func() {
+	Describe("JSON", func() {
+		It("should parse the ISO-8601 token TTLs", func() {
+			policy := &OAuthPolicy{}
+
+			err := json.Unmarshal([]byte("{\"href\":\"href\",\"accessTokenTtl\":\"PT1H\",\"refreshTokenTtl\":\"P60D\"}"), policy)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Href).To(Equal("href"))
+			Expect(policy.AccessTokenTTL).To(Equal(time.Hour))
+			Expect(policy.RefreshTokenTTL).To(Equal(60 * 24 * time.Hour))
+		})
+
+		It("should parse combined ISO-8601 durations", func() {
+			policy := &OAuthPolicy{}
+
+			err := json.Unmarshal([]byte("{\"accessTokenTtl\":\"P1DT2H30M15S\",\"refreshTokenTtl\":\"P2W\"}"), policy)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.AccessTokenTTL).To(Equal(26*time.Hour + 30*time.Minute + 15*time.Second))
+			Expect(policy.RefreshTokenTTL).To(Equal(14 * 24 * time.Hour))
+		})
+
+		It("should return an error for an invalid duration", func() {
+			policy := &OAuthPolicy{}
+
+			err := json.Unmarshal([]byte("{\"accessTokenTtl\":\"1 hour\"}"), policy)
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should marshal the token TTLs as ISO-8601 durations", func() {
+			policy := OAuthPolicy{AccessTokenTTL: 90 * time.Minute, RefreshTokenTTL: 30 * 24 * time.Hour}
+
+			jsonData, _ := json.Marshal(policy)
+
+			Expect(string(jsonData)).To(Equal("{\"accessTokenTtl\":\"PT1H30M\",\"refreshTokenTtl\":\"P30D\"}"))
+		})
+	})
+
+	Describe("GetOAuthPolicy", func() {
+		It("should return the application OAuth policy", func() {
+			policy, err := app.GetOAuthPolicy(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Href).NotTo(BeEmpty())
+			Expect(policy.AccessTokenTTL).To(Equal(time.Hour))
+		})
+	})
+
+	Describe("Update", func() {
+		It("should update the token TTLs", func() {
+			policy, _ := app.GetOAuthPolicy(ctx)
+			policy.AccessTokenTTL = 2 * time.Hour
+
+			err := policy.Update(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.AccessTokenTTL).To(Equal(2 * time.Hour))
+
+			policy.AccessTokenTTL = time.Hour
+			policy.Update(ctx)
+		})
+	})
+})
@@ -0,0 +1,265 @@
+package stormpath
+
+import (
+	"errors"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/net/context"
+)
+
+//TokenValidationStrategy selects how an access token is validated by ValidateTokenWithStrategy
+type TokenValidationStrategy int
+
+const (
+	//RemoteTokenValidation validates the token against Stormpath, revoked tokens are always detected
+	RemoteTokenValidation TokenValidationStrategy = iota
+	//LocalTokenValidation validates the token signature and claims locally without any network round trip,
+	//a revoked token would still be considered valid until it expires
+	LocalTokenValidation
+	//LocalTokenValidationWithRevocationCheck validates the token locally first and then against Stormpath
+	//to catch revoked tokens, invalid tokens are rejected without reaching Stormpath
+	LocalTokenValidationWithRevocationCheck
+)
+
+//Access token validation errors
+var (
+	ErrInvalidAccessToken = errors.New("invalid access token")
+	ErrAccessTokenExpired = errors.New("access token has expired")
+)
+
+//AccessTokens represents a paged result of AccessToken objects
+//
+//See: http://docs.stormpath.com/guides/token-management/
+type AccessTokens struct {
+	collectionResource
+	Items []AccessToken `json:"items"`
+}
+
+//Claims returns the JWT claims of the access token
+func (token *AccessToken) Claims() map[string]interface{} {
+	claims, _ := token.ExpandedJWT["claims"].(map[string]interface{})
+	return claims
+}
+
+//AccountHref returns the href of the account the access token was issued for
+func (token *AccessToken) AccountHref() string {
+	if token.Account != nil && token.Account.Href != "" {
+		return token.Account.Href
+	}
+	sub, _ := token.Claims()["sub"].(string)
+	return sub
+}
+
+//RefreshToken represents an OAuth2 refresh token issued for an account
+//
+//See: http://docs.stormpath.com/guides/token-management/
+type RefreshToken struct {
+	resource
+	Account     *Account               `json:"account,omitempty"`
+	Tenant      *Tenant                `json:"tenant,omitempty"`
+	Application *Application           `json:"application,omitempty"`
+	JWT         string                 `json:"jwt"`
+	ExpandedJWT map[string]interface{} `json:"expandedJwt"`
+}
+
+//RefreshTokens represents a paged result of RefreshToken objects
+type RefreshTokens struct {
+	collectionResource
+	Items []RefreshToken `json:"items"`
+}
+
+//GetAccessTokens returns a paged result of the OAuth2 access tokens issued for the given account
+//
+//See: http://docs.stormpath.com/guides/token-management/#revoking-access-and-refresh-tokens
+func (account *Account) GetAccessTokens(ctx context.Context, criteria Criteria) (*AccessTokens, error) {
+	accessTokens := &AccessTokens{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(account.AccessTokens.Href, criteria.ToQueryString()),
+		emptyPayload(),
+		accessTokens,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return accessTokens, nil
+}
+
+//GetRefreshTokens returns a paged result of the OAuth2 refresh tokens issued for the given account
+//
+//See: http://docs.stormpath.com/guides/token-management/#revoking-access-and-refresh-tokens
+func (account *Account) GetRefreshTokens(ctx context.Context, criteria Criteria) (*RefreshTokens, error) {
+	refreshTokens := &RefreshTokens{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(account.RefreshTokens.Href, criteria.ToQueryString()),
+		emptyPayload(),
+		refreshTokens,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return refreshTokens, nil
+}
+
+//RevokeAllTokens deletes every access and refresh token issued for the given account,
+//effectively logging the account out everywhere
+func (account *Account) RevokeAllTokens(ctx context.Context) error {
+	//Page through the tokens before deleting any of them, deleting while paging would shift the pages
+	hrefs := []string{}
+
+	for offset := 0; ; offset += 25 {
+		accessTokens, err := account.GetAccessTokens(ctx, MakeOAuthTokensCriteria().Offset(offset).Limit(25))
+		if err != nil {
+			return err
+		}
+		if len(accessTokens.Items) == 0 {
+			break
+		}
+		for _, t := range accessTokens.Items {
+			hrefs = append(hrefs, t.Href)
+		}
+	}
+
+	for offset := 0; ; offset += 25 {
+		refreshTokens, err := account.GetRefreshTokens(ctx, MakeOAuthTokensCriteria().Offset(offset).Limit(25))
+		if err != nil {
+			return err
+		}
+		if len(refreshTokens.Items) == 0 {
+			break
+		}
+		for _, t := range refreshTokens.Items {
+			hrefs = append(hrefs, t.Href)
+		}
+	}
+
+	client := getClient(ctx)
+	for _, href := range hrefs {
+		err := client.delete(href, emptyPayload())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//RevokeToken revokes a single access or refresh token issued by the application, the token type is taken from
+//its stt header. Expired tokens can still be revoked, tokens that weren't issued by the application are rejected
+//with ErrInvalidAccessToken.
+//
+//See: http://docs.stormpath.com/guides/token-management/#revoking-access-and-refresh-tokens
+func (app *Application) RevokeToken(ctx context.Context, token string) error {
+	client := getClient(ctx)
+
+	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, ErrInvalidAccessToken
+		}
+		return []byte(client.Credentials.Secret), nil
+	})
+	if err != nil {
+		if vErr, ok := err.(*jwt.ValidationError); !ok || vErr.Errors != jwt.ValidationErrorExpired {
+			return ErrInvalidAccessToken
+		}
+	}
+
+	if iss, _ := parsedToken.Claims["iss"].(string); iss != app.Href {
+		return ErrInvalidAccessToken
+	}
+
+	//The token resource ID is the JWT ID
+	jti, _ := parsedToken.Claims["jti"].(string)
+	if jti == "" {
+		return ErrInvalidAccessToken
+	}
+
+	switch parsedToken.Header["stt"] {
+	case "access":
+		return client.delete(buildRelativeURL("accessTokens", jti), emptyPayload())
+	case "refresh":
+		return client.delete(buildRelativeURL("refreshTokens", jti), emptyPayload())
+	}
+
+	return ErrInvalidAccessToken
+}
+
+//ValidateTokenWithStrategy validates an access token issued by the application using the given strategy.
+//
+//Stormpath access tokens are HS256 JWTs signed with the client API key secret, so the local validation
+//verifies the signature, the expiration, the issuer (the application href) and the token type, and returns
+//an AccessToken with the JWT claims and the account href taken from the token subject.
+func (app *Application) ValidateTokenWithStrategy(ctx context.Context, token string, strategy TokenValidationStrategy) (*AccessToken, error) {
+	if strategy == RemoteTokenValidation {
+		return app.ValidateToken(ctx, token)
+	}
+
+	accessToken, err := app.validateTokenLocally(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if strategy == LocalTokenValidationWithRevocationCheck {
+		return app.ValidateToken(ctx, token)
+	}
+
+	return accessToken, nil
+}
+
+func (app *Application) validateTokenLocally(ctx context.Context, token string) (*AccessToken, error) {
+	client := getClient(ctx)
+
+	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, ErrInvalidAccessToken
+		}
+		return []byte(client.Credentials.Secret), nil
+	})
+	if err != nil {
+		if vErr, ok := err.(*jwt.ValidationError); ok && vErr.Errors == jwt.ValidationErrorExpired {
+			return nil, ErrAccessTokenExpired
+		}
+		return nil, ErrInvalidAccessToken
+	}
+	if !parsedToken.Valid {
+		return nil, ErrInvalidAccessToken
+	}
+
+	//Stormpath tokens carry their type in the stt header, refresh tokens must not be accepted as access tokens
+	if parsedToken.Header["stt"] != "access" {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if _, ok := parsedToken.Claims["exp"].(float64); !ok {
+		return nil, ErrInvalidAccessToken
+	}
+
+	if iss, _ := parsedToken.Claims["iss"].(string); iss != app.Href {
+		return nil, ErrInvalidAccessToken
+	}
+
+	sub, _ := parsedToken.Claims["sub"].(string)
+	if sub == "" {
+		return nil, ErrInvalidAccessToken
+	}
+
+	accessToken := &AccessToken{
+		Account:     &Account{},
+		Application: &Application{},
+		JWT:         token,
+		ExpandedJWT: map[string]interface{}{
+			"header":    parsedToken.Header,
+			"claims":    parsedToken.Claims,
+			"signature": parsedToken.Signature,
+		},
+	}
+	accessToken.Account.Href = sub
+	accessToken.Application.Href = app.Href
+
+	return accessToken, nil
+}
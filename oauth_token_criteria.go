@@ -0,0 +1,42 @@
+package stormpath
+
+import "net/url"
+
+type OAuthTokenCriteria struct {
+	baseCriteria
+}
+
+func MakeOAuthTokenCriteria() OAuthTokenCriteria {
+	return OAuthTokenCriteria{baseCriteria{filter: url.Values{}}}
+}
+
+func MakeOAuthTokensCriteria() OAuthTokenCriteria {
+	return OAuthTokenCriteria{baseCriteria{limit: 25, filter: url.Values{}}}
+}
+
+//Filter related functions
+
+//Possible filters:
+//* application.href
+
+func (c OAuthTokenCriteria) ApplicationHrefEq(href string) OAuthTokenCriteria {
+	c.filter.Add("application.href", href)
+	return c
+}
+
+//Expansion related functions
+
+func (c OAuthTokenCriteria) WithAccount() OAuthTokenCriteria {
+	c.expandedAttributes = append(c.expandedAttributes, "account")
+	return c
+}
+
+func (c OAuthTokenCriteria) WithApplication() OAuthTokenCriteria {
+	c.expandedAttributes = append(c.expandedAttributes, "application")
+	return c
+}
+
+func (c OAuthTokenCriteria) WithTenant() OAuthTokenCriteria {
+	c.expandedAttributes = append(c.expandedAttributes, "tenant")
+	return c
+}
@@ -0,0 +1,160 @@
+package stormpath_test
+
+import (
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OAuth tokens", func() {
+	Describe("RefreshOAuthToken", func() {
+		It("should return a new access token for a valid refresh token", func() {
+			account := registerTestAccount(ctx)
+			response, _ := app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+
+			refreshed, err := app.RefreshOAuthToken(ctx, response.RefreshToken)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshed.AccessToken).NotTo(BeEmpty())
+			Expect(refreshed.AccessToken).NotTo(Equal(response.AccessToken))
+		})
+
+		It("should return an error for an invalid refresh token", func() {
+			refreshed, err := app.RefreshOAuthToken(ctx, "anInvalidToken")
+
+			Expect(err).To(HaveOccurred())
+			Expect(refreshed).To(BeNil())
+		})
+	})
+
+	Describe("account tokens", func() {
+		It("should list the account access and refresh tokens", func() {
+			account := registerTestAccount(ctx)
+			account.Refresh(ctx)
+			app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+
+			accessTokens, err := account.GetAccessTokens(ctx, MakeOAuthTokensCriteria())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(accessTokens.Items).To(HaveLen(1))
+
+			refreshTokens, err := account.GetRefreshTokens(ctx, MakeOAuthTokensCriteria().ApplicationHrefEq(app.Href))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refreshTokens.Items).To(HaveLen(1))
+		})
+
+		It("should revoke a single access token", func() {
+			account := registerTestAccount(ctx)
+			account.Refresh(ctx)
+			response, _ := app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+			token, _ := app.ValidateToken(ctx, response.AccessToken)
+
+			err := token.Delete(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.ValidateToken(ctx, response.AccessToken)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should revoke an access token and a refresh token by their JWT", func() {
+			account := registerTestAccount(ctx)
+			response, _ := app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+
+			err := app.RevokeToken(ctx, response.AccessToken)
+			Expect(err).NotTo(HaveOccurred())
+			err = app.RevokeToken(ctx, response.RefreshToken)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.ValidateToken(ctx, response.AccessToken)
+			Expect(err).To(HaveOccurred())
+			_, err = app.RefreshOAuthToken(ctx, response.RefreshToken)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should not revoke a token that wasn't issued by the application", func() {
+			err := app.RevokeToken(ctx, "anInvalidToken")
+
+			Expect(err).To(Equal(ErrInvalidAccessToken))
+		})
+
+		It("should revoke all the account tokens", func() {
+			account := registerTestAccount(ctx)
+			account.Refresh(ctx)
+			app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+			app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+
+			err := account.RevokeAllTokens(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			accessTokens, _ := account.GetAccessTokens(ctx, MakeOAuthTokensCriteria())
+			refreshTokens, _ := account.GetRefreshTokens(ctx, MakeOAuthTokensCriteria())
+			Expect(accessTokens.Items).To(BeEmpty())
+			Expect(refreshTokens.Items).To(BeEmpty())
+		})
+	})
+
+	Describe("ValidateTokenWithStrategy", func() {
+		newSignedToken := func(tokenType string, exp time.Time) string {
+			token := jwt.New(jwt.SigningMethodHS256)
+			token.Header["stt"] = tokenType
+			token.Claims["iss"] = app.Href
+			token.Claims["sub"] = account.Href
+			token.Claims["iat"] = time.Now().Unix()
+			token.Claims["exp"] = exp.Unix()
+			tokenString, _ := token.SignedString([]byte(cred.Secret))
+			return tokenString
+		}
+
+		It("should validate an access token locally", func() {
+			account := registerTestAccount(ctx)
+			response, _ := app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+
+			token, err := app.ValidateTokenWithStrategy(ctx, response.AccessToken, LocalTokenValidation)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(token.JWT).To(Equal(response.AccessToken))
+			Expect(token.AccountHref()).To(Equal(account.Href))
+			Expect(token.Claims()["iss"]).To(Equal(app.Href))
+		})
+
+		It("should reject a token with an invalid signature", func() {
+			token := jwt.New(jwt.SigningMethodHS256)
+			token.Header["stt"] = "access"
+			token.Claims["iss"] = app.Href
+			token.Claims["sub"] = account.Href
+			token.Claims["exp"] = time.Now().Add(time.Hour).Unix()
+			tokenString, _ := token.SignedString([]byte("not the secret"))
+
+			_, err := app.ValidateTokenWithStrategy(ctx, tokenString, LocalTokenValidation)
+
+			Expect(err).To(Equal(ErrInvalidAccessToken))
+		})
+
+		It("should reject an expired token", func() {
+			_, err := app.ValidateTokenWithStrategy(ctx, newSignedToken("access", time.Now().Add(-time.Hour)), LocalTokenValidation)
+
+			Expect(err).To(Equal(ErrAccessTokenExpired))
+		})
+
+		It("should reject a refresh token", func() {
+			_, err := app.ValidateTokenWithStrategy(ctx, newSignedToken("refresh", time.Now().Add(time.Hour)), LocalTokenValidation)
+
+			Expect(err).To(Equal(ErrInvalidAccessToken))
+		})
+
+		It("should only detect a revoked token when checking against Stormpath", func() {
+			account := registerTestAccount(ctx)
+			response, _ := app.GetOAuthToken(ctx, account.Username, "1234567z!A89")
+			token, _ := app.ValidateToken(ctx, response.AccessToken)
+			token.Delete(ctx)
+
+			_, err := app.ValidateTokenWithStrategy(ctx, response.AccessToken, LocalTokenValidation)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = app.ValidateTokenWithStrategy(ctx, response.AccessToken, LocalTokenValidationWithRevocationCheck)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,94 @@
+package stormpath
+
+import "golang.org/x/net/context"
+
+//Organization represents a Stormpath organization, an account store identified by its nameKey
+//that groups directories and groups, usually a tenant of a multi-tenant application
+//
+//See: http://docs.stormpath.com/rest/product-guide/#organizations
+type Organization struct {
+	accountStoreResource
+	Name                       string                            `json:"name,omitempty"`
+	NameKey                    string                            `json:"nameKey,omitempty"`
+	Description                string                            `json:"description,omitempty"`
+	Status                     string                            `json:"status,omitempty"`
+	Groups                     *Groups                           `json:"groups,omitempty"`
+	Tenant                     *Tenant                           `json:"tenant,omitempty"`
+	AccountStoreMappings       *OrganizationAccountStoreMappings `json:"accountStoreMappings,omitempty"`
+	DefaultAccountStoreMapping *OrganizationAccountStoreMapping  `json:"defaultAccountStoreMapping,omitempty"`
+	DefaultGroupStoreMapping   *OrganizationAccountStoreMapping  `json:"defaultGroupStoreMapping,omitempty"`
+}
+
+//Organizations represents a paged result of organizations
+type Organizations struct {
+	collectionResource
+	Items []Organization `json:"items"`
+}
+
+//NewOrganization creates a new organization with the given name and name key
+func NewOrganization(name string, nameKey string) *Organization {
+	return &Organization{Name: name, NameKey: nameKey}
+}
+
+//GetOrganization loads an organization by href and criteria
+func GetOrganization(ctx context.Context, href string, criteria Criteria) (*Organization, error) {
+	organization := &Organization{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(href, criteria.ToQueryString()),
+		emptyPayload(),
+		organization,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return organization, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (org *Organization) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(org.Href, emptyPayload(), org)
+}
+
+//Update updates the given resource, by doing a POST to the resource Href
+func (org *Organization) Update(ctx context.Context) error {
+	return getClient(ctx).post(org.Href, org, org)
+}
+
+//GetAccountStoreMappings returns all the organization account store mappings
+//
+//See: http://docs.stormpath.com/rest/product-guide/#organization-account-store-mappings
+func (org *Organization) GetAccountStoreMappings(ctx context.Context, criteria Criteria) (*OrganizationAccountStoreMappings, error) {
+	accountStoreMappings := &OrganizationAccountStoreMappings{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(org.AccountStoreMappings.Href, criteria.ToQueryString()),
+		emptyPayload(),
+		accountStoreMappings,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return accountStoreMappings, nil
+}
+
+//GetGroups returns all the organization groups
+func (org *Organization) GetGroups(ctx context.Context, criteria Criteria) (*Groups, error) {
+	groups := &Groups{}
+
+	err := getClient(ctx).get(
+		buildAbsoluteURL(org.Groups.Href, criteria.ToQueryString()),
+		emptyPayload(),
+		groups,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
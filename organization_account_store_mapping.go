@@ -0,0 +1,42 @@
+package stormpath
+
+import "golang.org/x/net/context"
+
+//OrganizationAccountStoreMapping represents a mapping between an organization and one of its
+//account stores (directory or group)
+//
+//See: http://docs.stormpath.com/rest/product-guide/#organization-account-store-mappings
+type OrganizationAccountStoreMapping struct {
+	resource
+	ListIndex             *int         `json:"listIndex,omitempty"`
+	IsDefaultAccountStore *bool        `json:"isDefaultAccountStore,omitempty"`
+	IsDefaultGroupStore   *bool        `json:"isDefaultGroupStore,omitempty"`
+	Organization          Organization `json:"organization"`
+	AccountStore          resource     `json:"accountStore"`
+}
+
+//OrganizationAccountStoreMappings represents a paged result of organization account store mappings
+type OrganizationAccountStoreMappings struct {
+	collectionResource
+	Items []OrganizationAccountStoreMapping `json:"items"`
+}
+
+//NewOrganizationAccountStoreMapping creates a new organization account store mapping
+func NewOrganizationAccountStoreMapping(organizationHref string, accountStoreHref string) *OrganizationAccountStoreMapping {
+	org := Organization{}
+	org.Href = organizationHref
+	return &OrganizationAccountStoreMapping{
+		Organization: org,
+		AccountStore: resource{Href: accountStoreHref},
+	}
+}
+
+//Save saves the given organization account store mapping
+func (mapping *OrganizationAccountStoreMapping) Save(ctx context.Context) error {
+	url := buildRelativeURL("organizationAccountStoreMappings")
+	if mapping.Href != "" {
+		url = mapping.Href
+	}
+
+	return getClient(ctx).post(url, mapping, mapping)
+}
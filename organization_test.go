@@ -0,0 +1,151 @@
+package stormpath_test
+
+import (
+	"encoding/json"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func newTestOrganization() *Organization {
+	name := randomName()
+	return NewOrganization("org-"+name, "org-"+name[:8])
+}
+
+//newTestOrganizationAccount creates an organization backed by a new directory, maps it to the test application
+//and registers an account in the directory
+func newTestOrganizationAccount() (*Organization, *Account) {
+	org := newTestOrganization()
+	tenant.CreateOrganization(ctx, org)
+
+	dir := newTestDirectory()
+	tenant.CreateDirectory(ctx, dir)
+	NewOrganizationAccountStoreMapping(org.Href, dir.Href).Save(ctx)
+	NewAccountStoreMapping(app.Href, org.Href).Save(ctx)
+
+	account := newTestAccount()
+	dir.RegisterAccount(ctx, account)
+
+	return org, account
+}
+
+var _ = Describe("Organization", func() {
+	Describe("JSON", func() {
+		It("should marshall a minimum JSON with only the required fields", func() {
+			org := NewOrganization("name", "nameKey")
+
+			jsonData, _ := json.Marshal(org)
+
+			Expect(string(jsonData)).To(Equal("{\"name\":\"name\",\"nameKey\":\"nameKey\"}"))
+		})
+
+		It("should marshall a minimum account store mapping JSON with only the required fields", func() {
+			mapping := NewOrganizationAccountStoreMapping("http://orgurl", "http://storeUrl")
+
+			jsonData, _ := json.Marshal(mapping)
+
+			Expect(string(jsonData)).To(Equal("{\"organization\":{\"href\":\"http://orgurl\"},\"accountStore\":{\"href\":\"http://storeUrl\"}}"))
+		})
+	})
+
+	Describe("tenant organizations", func() {
+		It("should create a new organization", func() {
+			org := newTestOrganization()
+
+			err := tenant.CreateOrganization(ctx, org)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(org.Href).NotTo(BeEmpty())
+			Expect(org.Status).To(Equal(Enabled))
+			org.Delete(ctx)
+		})
+
+		It("should return an error if the organization has no name key", func() {
+			err := tenant.CreateOrganization(ctx, &Organization{Name: "org-" + randomName()})
+
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should find an organization by name key", func() {
+			org := newTestOrganization()
+			tenant.CreateOrganization(ctx, org)
+
+			orgs, err := tenant.GetOrganizations(ctx, MakeOrganizationsCriteria().NameKeyEq(org.NameKey))
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(orgs.Items).To(HaveLen(1))
+			Expect(orgs.Items[0].Href).To(Equal(org.Href))
+			org.Delete(ctx)
+		})
+
+		It("should retrieve an organization by href", func() {
+			org := newTestOrganization()
+			tenant.CreateOrganization(ctx, org)
+
+			o, err := GetOrganization(ctx, org.Href, MakeOrganizationCriteria())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(o.NameKey).To(Equal(org.NameKey))
+			org.Delete(ctx)
+		})
+	})
+
+	Describe("account store mappings", func() {
+		It("should map a directory to the organization", func() {
+			org := newTestOrganization()
+			tenant.CreateOrganization(ctx, org)
+			dir := newTestDirectory()
+			tenant.CreateDirectory(ctx, dir)
+
+			err := NewOrganizationAccountStoreMapping(org.Href, dir.Href).Save(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			mappings, err := org.GetAccountStoreMappings(ctx, MakeAccountStoreMappingsCriteria())
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mappings.Items).To(HaveLen(1))
+			Expect(mappings.Items[0].AccountStore.Href).To(Equal(dir.Href))
+			org.Delete(ctx)
+			dir.Delete(ctx)
+		})
+	})
+
+	Describe("organization authentication", func() {
+		It("should authenticate an account scoped by the organization name key", func() {
+			org, account := newTestOrganizationAccount()
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{OrganizationNameKey: org.NameKey})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Href).To(Equal(account.Href))
+		})
+
+		It("should authenticate an account scoped by the organization href", func() {
+			org, account := newTestOrganizationAccount()
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{OrganizationHref: org.Href})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(a.Href).To(Equal(account.Href))
+		})
+
+		It("should not authenticate an account outside of the organization", func() {
+			org, _ := newTestOrganizationAccount()
+
+			a, err := app.AuthenticateAccount(ctx, account.Email, "1234567z!A89", AuthenticationOptions{OrganizationNameKey: org.NameKey})
+
+			Expect(err).To(HaveOccurred())
+			Expect(a).To(BeNil())
+		})
+
+		It("should issue an OAuth token scoped by the organization name key", func() {
+			org, account := newTestOrganizationAccount()
+
+			response, err := app.GetOAuthToken(ctx, account.Email, "1234567z!A89", AuthenticationOptions{OrganizationNameKey: org.NameKey})
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.AccessToken).NotTo(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,175 @@
+package stormpath
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/context"
+)
+
+//PasswordPolicy represents a directory password policy object, it holds the password reset workflow
+//settings and the password strength rules of the directory accounts
+//
+//See: http://docs.stormpath.com/rest/product-guide/#directory-password-policy
+type PasswordPolicy struct {
+	resource
+	ResetTokenTTL              int               `json:"resetTokenTtl,omitempty"`
+	ResetEmailStatus           string            `json:"resetEmailStatus,omitempty"`
+	ResetEmailTemplates        *EmailTemplates   `json:"resetEmailTemplates,omitempty"`
+	ResetSuccessEmailStatus    string            `json:"resetSuccessEmailStatus,omitempty"`
+	ResetSuccessEmailTemplates *EmailTemplates   `json:"resetSuccessEmailTemplates,omitempty"`
+	Strength                   *PasswordStrength `json:"strength,omitempty"`
+}
+
+//PasswordStrength represents the password strength rules of a directory password policy,
+//PreventReuse is the number of previous passwords that can't be reused
+//
+//See: http://docs.stormpath.com/rest/product-guide/#directory-password-policy
+type PasswordStrength struct {
+	resource
+	MinLength    int `json:"minLength"`
+	MaxLength    int `json:"maxLength"`
+	MinLowerCase int `json:"minLowerCase"`
+	MinUpperCase int `json:"minUpperCase"`
+	MinNumeric   int `json:"minNumeric"`
+	MinSymbol    int `json:"minSymbol"`
+	MinDiacritic int `json:"minDiacritic"`
+	PreventReuse int `json:"preventReuse"`
+}
+
+//PasswordStrengthError is returned by PasswordStrength.Validate, it lists all the rules the password violates
+type PasswordStrengthError struct {
+	Violations []string
+}
+
+func (e PasswordStrengthError) Error() string {
+	return "password doesn't meet the strength requirements: " + strings.Join(e.Violations, ", ")
+}
+
+//GetPasswordPolicy loads the directory password policy with its strength rules
+func (dir *Directory) GetPasswordPolicy(ctx context.Context) (*PasswordPolicy, error) {
+	err := dir.PasswordPolicy.Refresh(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dir.PasswordPolicy, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (policy *PasswordPolicy) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(policy.Href+"?expand=strength", emptyPayload(), policy)
+}
+
+//Update updates the given resource, by doing a POST to the resource Href, the strength rules
+//are a resource on their own and are updated with PasswordStrength.Update
+func (policy *PasswordPolicy) Update(ctx context.Context) error {
+	strength := policy.Strength
+	policy.Strength = nil
+
+	err := getClient(ctx).post(policy.Href, policy, policy)
+
+	policy.Strength = strength
+	return err
+}
+
+//EnableResetSuccessEmail enables the email sent to the account after its password was reset
+func (policy *PasswordPolicy) EnableResetSuccessEmail(ctx context.Context) error {
+	policy.ResetSuccessEmailStatus = Enabled
+	return policy.Update(ctx)
+}
+
+//DisableResetSuccessEmail disables the email sent to the account after its password was reset
+func (policy *PasswordPolicy) DisableResetSuccessEmail(ctx context.Context) error {
+	policy.ResetSuccessEmailStatus = Disabled
+	return policy.Update(ctx)
+}
+
+//GetResetEmailTemplates loads the policy ResetEmailTemplates collection and returns it
+func (policy *PasswordPolicy) GetResetEmailTemplates(ctx context.Context) (*EmailTemplates, error) {
+	err := getClient(ctx).get(policy.ResetEmailTemplates.Href, emptyPayload(), policy.ResetEmailTemplates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.ResetEmailTemplates, nil
+}
+
+//GetResetSuccessEmailTemplates loads the policy ResetSuccessEmailTemplates collection and returns it
+func (policy *PasswordPolicy) GetResetSuccessEmailTemplates(ctx context.Context) (*EmailTemplates, error) {
+	err := getClient(ctx).get(policy.ResetSuccessEmailTemplates.Href, emptyPayload(), policy.ResetSuccessEmailTemplates)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.ResetSuccessEmailTemplates, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (strength *PasswordStrength) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(strength.Href, emptyPayload(), strength)
+}
+
+//Update updates the given resource, by doing a POST to the resource Href
+func (strength *PasswordStrength) Update(ctx context.Context) error {
+	return getClient(ctx).post(strength.Href, strength, strength)
+}
+
+//Validate checks the password against the strength rules the same way Stormpath does when an account
+//is created or its password changed, so violations can be reported before calling the API.
+//PreventReuse can only be enforced by Stormpath and is not checked. Returns a PasswordStrengthError
+//if the password violates any rule.
+func (strength PasswordStrength) Validate(password string) error {
+	var lower, upper, numeric, symbol, diacritic int
+
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower++
+		case r >= 'A' && r <= 'Z':
+			upper++
+		case unicode.IsDigit(r):
+			numeric++
+		case unicode.IsLetter(r):
+			diacritic++
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			symbol++
+		}
+	}
+
+	var violations []string
+	length := utf8.RuneCountInString(password)
+
+	if length < strength.MinLength {
+		violations = append(violations, fmt.Sprintf("minimum length is %d", strength.MinLength))
+	}
+	if strength.MaxLength > 0 && length > strength.MaxLength {
+		violations = append(violations, fmt.Sprintf("maximum length is %d", strength.MaxLength))
+	}
+	if lower < strength.MinLowerCase {
+		violations = append(violations, fmt.Sprintf("requires at least %d lower case characters", strength.MinLowerCase))
+	}
+	if upper < strength.MinUpperCase {
+		violations = append(violations, fmt.Sprintf("requires at least %d upper case characters", strength.MinUpperCase))
+	}
+	if numeric < strength.MinNumeric {
+		violations = append(violations, fmt.Sprintf("requires at least %d numeric characters", strength.MinNumeric))
+	}
+	if symbol < strength.MinSymbol {
+		violations = append(violations, fmt.Sprintf("requires at least %d symbols", strength.MinSymbol))
+	}
+	if diacritic < strength.MinDiacritic {
+		violations = append(violations, fmt.Sprintf("requires at least %d diacritic characters", strength.MinDiacritic))
+	}
+
+	if len(violations) > 0 {
+		return PasswordStrengthError{Violations: violations}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package stormpath_test
+
+import (
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PasswordPolicy", func() {
+	Describe("GetPasswordPolicy", func() {
+		It("should retrieve the directory password policy with its strength", func() {
+			directory := newTestDirectory()
+			tenant.CreateDirectory(ctx, directory)
+
+			policy, err := directory.GetPasswordPolicy(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.ResetEmailStatus).To(Equal(Enabled))
+			Expect(policy.Strength.MinLength).To(Equal(8))
+			Expect(policy.Strength.MaxLength).To(Equal(100))
+			directory.Delete(ctx)
+		})
+	})
+
+	Describe("Update", func() {
+		It("should update the policy and its strength", func() {
+			directory := newTestDirectory()
+			tenant.CreateDirectory(ctx, directory)
+			policy, _ := directory.GetPasswordPolicy(ctx)
+
+			policy.ResetTokenTTL = 48
+			err := policy.Update(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			policy.Strength.MinSymbol = 1
+			err = policy.Strength.Update(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			policy.Refresh(ctx)
+			Expect(policy.ResetTokenTTL).To(Equal(48))
+			Expect(policy.Strength.MinSymbol).To(Equal(1))
+			directory.Delete(ctx)
+		})
+	})
+
+	Describe("EnableResetSuccessEmail", func() {
+		It("should enable and disable the reset success email", func() {
+			directory := newTestDirectory()
+			tenant.CreateDirectory(ctx, directory)
+			policy, _ := directory.GetPasswordPolicy(ctx)
+
+			err := policy.EnableResetSuccessEmail(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.ResetSuccessEmailStatus).To(Equal(Enabled))
+
+			err = policy.DisableResetSuccessEmail(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.ResetSuccessEmailStatus).To(Equal(Disabled))
+			directory.Delete(ctx)
+		})
+	})
+
+	Describe("Strength.Validate", func() {
+		strength := PasswordStrength{MinLength: 8, MaxLength: 12, MinLowerCase: 1, MinUpperCase: 1, MinNumeric: 1, MinSymbol: 1, MinDiacritic: 1}
+
+		It("should accept a password that meets all the rules", func() {
+			Expect(strength.Validate("aB1!é789")).To(Succeed())
+		})
+
+		It("should report every violated rule", func() {
+			err := strength.Validate("abc")
+
+			Expect(err).To(HaveOccurred())
+			Expect(err.(PasswordStrengthError).Violations).To(HaveLen(5))
+		})
+
+		It("should reject a password that is too long", func() {
+			err := strength.Validate("aB1!éaaaaaaaaaaa")
+
+			Expect(err.(PasswordStrengthError).Violations).To(Equal([]string{"maximum length is 12"}))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package stormpath
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/nu7hatch/gouuid"
+	"golang.org/x/net/context"
+)
+
+//SAMLPolicy represents an application SAML policy object
+//
+//See: http://docs.stormpath.com/rest/product-guide/#saml-policy
+type SAMLPolicy struct {
+	resource
+	ServiceProvider *SAMLServiceProvider `json:"serviceProvider,omitempty"`
+}
+
+//SAMLServiceProvider represents the SAML service provider of an application, it holds the endpoint
+//that initiates the SAML flow by redirecting to the IdP of the application SAML directories
+type SAMLServiceProvider struct {
+	resource
+	SSOInitiationEndpoint *resource `json:"ssoInitiationEndpoint,omitempty"`
+	DefaultRelayStates    *resource `json:"defaultRelayStates,omitempty"`
+}
+
+//SAMLOptions holds the parameters of a SAML IdP redirect URL, CallbackURI must be one of the
+//application authorized callback URIs, see CreateIDSiteURLWithOptions for the matching rules
+type SAMLOptions struct {
+	CallbackURI         string
+	State               string
+	AccountStoreHref    string
+	OrganizationNameKey string
+}
+
+//GetSAMLPolicy loads the application SAML policy
+//
+//See: http://docs.stormpath.com/rest/product-guide/#saml-policy
+func (app *Application) GetSAMLPolicy(ctx context.Context) (*SAMLPolicy, error) {
+	policy := &SAMLPolicy{}
+
+	err := getClient(ctx).get(app.SAMLPolicy.Href, emptyPayload(), policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+//Refresh refreshes the resource by doing a GET to the resource href endpoint
+func (policy *SAMLPolicy) Refresh(ctx context.Context) error {
+	return getClient(ctx).get(policy.Href, emptyPayload(), policy)
+}
+
+//GetServiceProvider loads the SAML policy service provider
+func (policy *SAMLPolicy) GetServiceProvider(ctx context.Context) (*SAMLServiceProvider, error) {
+	err := getClient(ctx).get(policy.ServiceProvider.Href, emptyPayload(), policy.ServiceProvider)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return policy.ServiceProvider, nil
+}
+
+//CreateSAMLURL creates the URL that starts a SAML login, it redirects the user to the IdP and after the login
+//the user is sent back to the callback URI with a signed jwtResponse to be handled by HandleSAMLCallback
+//
+//See: http://docs.stormpath.com/rest/product-guide/#saml-authentication
+func (app *Application) CreateSAMLURL(ctx context.Context, options SAMLOptions) (string, error) {
+	err := app.validateCallbackURI(options.CallbackURI)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.New(jwt.SigningMethodHS256)
+
+	nonce, _ := uuid.NewV4()
+
+	token.Claims["jti"] = nonce.String()
+	token.Claims["iat"] = time.Now().Unix()
+	token.Claims["iss"] = getClient(ctx).Credentials.ID
+	token.Claims["sub"] = app.Href
+	token.Claims["cb_uri"] = options.CallbackURI
+	token.Claims["state"] = options.State
+	if options.AccountStoreHref != "" {
+		token.Claims["ash"] = options.AccountStoreHref
+	}
+	if options.OrganizationNameKey != "" {
+		token.Claims["onk"] = options.OrganizationNameKey
+	}
+
+	tokenString, err := token.SignedString([]byte(getClient(ctx).Credentials.Secret))
+	if err != nil {
+		return "", err
+	}
+
+	return buildAbsoluteURL(app.Href, "saml/sso/idpRedirect") + "?accessToken=" + url.QueryEscape(tokenString), nil
+}
+
+//HandleSAMLCallback handles the URL from a SAML callback, the jwtResponse has the same format than
+//the ID Site one so it is validated the same way and returns an IDSiteCallbackResult
+func (app *Application) HandleSAMLCallback(ctx context.Context, URL string, options ...IDSiteCallbackOptions) (*IDSiteCallbackResult, error) {
+	return app.HandleIDSiteCallback(ctx, URL, options...)
+}
@@ -0,0 +1,86 @@
+package stormpath_test
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SAML", func() {
+	Describe("GetSAMLPolicy", func() {
+		It("should return the application SAML policy and service provider", func() {
+			policy, err := app.GetSAMLPolicy(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Href).NotTo(BeEmpty())
+
+			serviceProvider, err := policy.GetServiceProvider(ctx)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(serviceProvider.SSOInitiationEndpoint.Href).NotTo(BeEmpty())
+		})
+	})
+
+	Describe("CreateSAMLURL", func() {
+		It("should create a valid SAML IdP redirect URL", func() {
+			samlURL, err := app.CreateSAMLURL(ctx, SAMLOptions{CallbackURI: "http://localhost:8080", State: "state"})
+
+			u, _ := url.Parse(samlURL)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samlURL).To(HavePrefix(app.Href + "/saml/sso/idpRedirect"))
+
+			token, _ := jwt.Parse(u.Query().Get("accessToken"), func(token *jwt.Token) (interface{}, error) {
+				return []byte(cred.Secret), nil
+			})
+
+			Expect(token.Valid).To(BeTrue())
+			Expect(token.Claims["cb_uri"]).To(Equal("http://localhost:8080"))
+			Expect(token.Claims["state"]).To(Equal("state"))
+			Expect(token.Claims["iss"]).To(Equal(cred.ID))
+			Expect(token.Claims["sub"]).To(Equal(app.Href))
+			Expect(token.Claims["jti"]).NotTo(BeEmpty())
+			Expect(token.Claims).NotTo(HaveKey("ash"))
+			Expect(token.Claims).NotTo(HaveKey("onk"))
+		})
+
+		It("should reject a callback URI that is not authorized", func() {
+			a := *app
+			a.AuthorizedCallbackURIs = []string{"https://example.com/callback"}
+
+			samlURL, err := a.CreateSAMLURL(ctx, SAMLOptions{CallbackURI: "https://evil.com/callback"})
+
+			Expect(err).To(Equal(ErrUnauthorizedCallbackURI))
+			Expect(samlURL).To(BeEmpty())
+
+			_, err = a.CreateSAMLURL(ctx, SAMLOptions{CallbackURI: "/callback"})
+
+			Expect(err).To(Equal(ErrInvalidCallbackURI))
+		})
+	})
+
+	Describe("HandleSAMLCallback", func() {
+		It("should return the authenticated account", func() {
+			token := jwt.New(jwt.SigningMethodHS256)
+			token.Claims["aud"] = cred.ID
+			token.Claims["iss"] = app.Href
+			token.Claims["sub"] = account.Href
+			token.Claims["exp"] = time.Now().Add(time.Minute).Unix()
+			token.Claims["status"] = "AUTHENTICATED"
+			token.Claims["state"] = "state"
+			token.Claims["irt"] = randomName()
+			jwtResponse, _ := token.SignedString([]byte(cred.Secret))
+
+			result, err := app.HandleSAMLCallback(ctx, "http://localhost:8080/callback?jwtResponse="+jwtResponse)
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Account.Href).To(Equal(account.Href))
+			Expect(result.State).To(Equal("state"))
+			Expect(result.Status).To(Equal(IDSiteAuthenticated))
+		})
+	})
+})
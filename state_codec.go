@@ -0,0 +1,198 @@
+package stormpath
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//State codec errors
+var (
+	ErrInvalidState        = errors.New("ID Site state is malformed or its signature is invalid")
+	ErrStateExpired        = errors.New("ID Site state has expired")
+	ErrInvalidStateKey     = errors.New("ID Site state signing key must be at least 32 bytes")
+	ErrUnsafeStateReturnTo = errors.New("ID Site state return to URI must be a path on the current host")
+)
+
+//MinStateSigningKeyLength is the minimum length in bytes of StateCodec.SigningKey
+const MinStateSigningKeyLength = 32
+
+//DefaultStateTTL is the state expiration used when StateCodec.TTL is not set
+const DefaultStateTTL = 1 * time.Hour
+
+//StateCodec serializes a value into a tamper proof ID Site state (IDSiteOptions.State) and verifies it back from
+//IDSiteCallbackResult.State. The state is HMAC-SHA256 signed with SigningKey, which is required and must be at least
+//MinStateSigningKeyLength bytes, and expires after TTL. When EncryptionKey is set (16, 24 or 32 bytes) the value
+//is also AES-GCM encrypted so it can't be read by the user.
+//
+//The state carries the URI to return to once the ID Site flow completes, it must be a local path
+//(see IsSafeRedirectURI) and is checked again on Decode so a state can't lead to an open redirect.
+type StateCodec struct {
+	SigningKey    []byte
+	EncryptionKey []byte
+	TTL           time.Duration
+}
+
+//statePayload is the signed content of an encoded state
+type statePayload struct {
+	ExpiresAt int64           `json:"exp"`
+	ReturnTo  string          `json:"rt,omitempty"`
+	Data      json.RawMessage `json:"data"`
+}
+
+//Encode serializes the return to URI, which can be empty, and v as JSON and returns the signed
+//(and optionally encrypted) state
+func (codec StateCodec) Encode(returnTo string, v interface{}) (string, error) {
+	if len(codec.SigningKey) < MinStateSigningKeyLength {
+		return "", ErrInvalidStateKey
+	}
+
+	if returnTo != "" && !IsSafeRedirectURI(returnTo) {
+		return "", ErrUnsafeStateReturnTo
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	ttl := codec.TTL
+	if ttl == 0 {
+		ttl = DefaultStateTTL
+	}
+
+	payload, err := json.Marshal(statePayload{ExpiresAt: time.Now().Add(ttl).Unix(), ReturnTo: returnTo, Data: data})
+	if err != nil {
+		return "", err
+	}
+
+	if codec.EncryptionKey != nil {
+		payload, err = codec.encrypt(payload)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encodedPayload + "." + codec.sign(encodedPayload), nil
+}
+
+//Decode verifies the state signature and expiration, decodes its value into v unless v is nil
+//and returns its return to URI
+func (codec StateCodec) Decode(state string, v interface{}) (string, error) {
+	if len(codec.SigningKey) < MinStateSigningKeyLength {
+		return "", ErrInvalidStateKey
+	}
+
+	parts := strings.Split(state, ".")
+	if len(parts) != 2 {
+		return "", ErrInvalidState
+	}
+
+	if !hmac.Equal([]byte(codec.sign(parts[0])), []byte(parts[1])) {
+		return "", ErrInvalidState
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidState
+	}
+
+	if codec.EncryptionKey != nil {
+		payload, err = codec.decrypt(payload)
+		if err != nil {
+			return "", ErrInvalidState
+		}
+	}
+
+	p := statePayload{}
+	err = json.Unmarshal(payload, &p)
+	if err != nil {
+		return "", ErrInvalidState
+	}
+
+	if time.Now().Unix() > p.ExpiresAt {
+		return "", ErrStateExpired
+	}
+
+	if p.ReturnTo != "" && !IsSafeRedirectURI(p.ReturnTo) {
+		return "", ErrUnsafeStateReturnTo
+	}
+
+	if v != nil {
+		err = json.Unmarshal(p.Data, v)
+		if err != nil {
+			return "", ErrInvalidState
+		}
+	}
+
+	return p.ReturnTo, nil
+}
+
+func (codec StateCodec) sign(encodedPayload string) string {
+	mac := hmac.New(sha256.New, codec.SigningKey)
+	mac.Write([]byte(encodedPayload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (codec StateCodec) encrypt(plaintext []byte) ([]byte, error) {
+	gcm, err := codec.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (codec StateCodec) decrypt(ciphertext []byte) ([]byte, error) {
+	gcm, err := codec.gcm()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, ErrInvalidState
+	}
+
+	return gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+}
+
+func (codec StateCodec) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(codec.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+//IsSafeRedirectURI returns true if the URI is a path on the current host ("/app?x=y"), absolute and
+//protocol relative ("//evil.com") URIs are rejected to prevent open redirects
+func IsSafeRedirectURI(uri string) bool {
+	if !strings.HasPrefix(uri, "/") || strings.HasPrefix(uri, "//") || strings.HasPrefix(uri, "/\\") {
+		return false
+	}
+
+	u, err := url.Parse(uri)
+	if err != nil {
+		return false
+	}
+
+	return u.Scheme == "" && u.Host == ""
+}
@@ -0,0 +1,109 @@
+package stormpath_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/sappenin/stormpath-sdk-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testState struct {
+	Nonce string `json:"nonce"`
+}
+
+var _ = Describe("StateCodec", func() {
+	signingKey := []byte("0123456789abcdef0123456789abcdef")
+	codec := StateCodec{SigningKey: signingKey}
+	encryptedCodec := StateCodec{SigningKey: signingKey, EncryptionKey: []byte("0123456789abcdef")}
+
+	It("should encode and decode a signed state", func() {
+		state, err := codec.Encode("/app", testState{Nonce: "nonce"})
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded := testState{}
+		returnTo, err := codec.Decode(state, &decoded)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnTo).To(Equal("/app"))
+		Expect(decoded).To(Equal(testState{Nonce: "nonce"}))
+	})
+
+	It("should encode and decode an encrypted state", func() {
+		state, err := encryptedCodec.Encode("/app", testState{Nonce: "nonce"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = codec.Decode(state, &testState{})
+		Expect(err).To(Equal(ErrInvalidState))
+
+		decoded := testState{}
+		returnTo, err := encryptedCodec.Decode(state, &decoded)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnTo).To(Equal("/app"))
+		Expect(decoded.Nonce).To(Equal("nonce"))
+	})
+
+	It("should decode a state without a return to URI or data", func() {
+		state, _ := codec.Encode("", nil)
+
+		returnTo, err := codec.Decode(state, nil)
+
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnTo).To(BeEmpty())
+	})
+
+	It("should reject a tampered state", func() {
+		state, _ := codec.Encode("/app", nil)
+		forged, _ := codec.Encode("/admin", nil)
+
+		tampered := strings.Split(forged, ".")[0] + "." + strings.Split(state, ".")[1]
+
+		_, err := codec.Decode(tampered, nil)
+		Expect(err).To(Equal(ErrInvalidState))
+		_, err = codec.Decode("garbage", nil)
+		Expect(err).To(Equal(ErrInvalidState))
+	})
+
+	It("should reject a state signed with another key", func() {
+		state, _ := StateCodec{SigningKey: []byte("another key of at least 32 bytes")}.Encode("/app", nil)
+
+		_, err := codec.Decode(state, nil)
+
+		Expect(err).To(Equal(ErrInvalidState))
+	})
+
+	It("should reject an expired state", func() {
+		state, _ := StateCodec{SigningKey: signingKey, TTL: -time.Minute}.Encode("/app", nil)
+
+		_, err := codec.Decode(state, nil)
+
+		Expect(err).To(Equal(ErrStateExpired))
+	})
+
+	It("should require a signing key of at least 32 bytes", func() {
+		for _, c := range []StateCodec{{}, {SigningKey: []byte("signing key")}} {
+			_, err := c.Encode("/app", nil)
+			Expect(err).To(Equal(ErrInvalidStateKey))
+
+			_, err = c.Decode("payload.signature", nil)
+			Expect(err).To(Equal(ErrInvalidStateKey))
+		}
+	})
+
+	It("should not encode a return to URI on another host", func() {
+		_, err := codec.Encode("https://evil.com", nil)
+
+		Expect(err).To(Equal(ErrUnsafeStateReturnTo))
+	})
+
+	Describe("IsSafeRedirectURI", func() {
+		It("should only accept local paths", func() {
+			Expect(IsSafeRedirectURI("/app?x=y")).To(BeTrue())
+			Expect(IsSafeRedirectURI("https://evil.com")).To(BeFalse())
+			Expect(IsSafeRedirectURI("//evil.com")).To(BeFalse())
+			Expect(IsSafeRedirectURI("/\\evil.com")).To(BeFalse())
+			Expect(IsSafeRedirectURI("app")).To(BeFalse())
+		})
+	})
+})
@@ -27,6 +27,7 @@ const version = "0.1.0-beta.12"
 const (
 	Enabled = "ENABLED"
 	Disabled = "DISABLED"
+	Unverified = "UNVERIFIED"
 	ApplicationJson = "application/json"
 	ApplicationFormURLencoded = "application/x-www-form-urlencoded"
 )
@@ -55,6 +56,20 @@ func Init(credentials Credentials, cache *cache.Cache) {
 	}
 }
 
+//EvictCache removes the cached response of the resource href fetched with the given criteria, e.g. by
+//GetAccount(ctx, href, criteria). The client only evicts the href of a resource when it is updated or deleted,
+//its expanded responses are kept until they expire unless they are evicted once the expanded data changes.
+func EvictCache(href string, criteria Criteria) {
+	if _cache == nil {
+		return
+	}
+
+	u, err := url.Parse(buildAbsoluteURL(href, criteria.ToQueryString()))
+	if err == nil {
+		_cache.Del(u.String())
+	}
+}
+
 func getClient(ctx context.Context) *Client {
 	trans := urlfetch.Transport{Context:ctx, AllowInvalidServerCertificate:false}
 	httpClient := &http.Client{Transport: &trans}
@@ -202,6 +217,9 @@ func (client *Client) doWithResult(request *http.Request, result interface{}) er
 		err = client.Cache.Get(key, result)
 	} else {
 		response, err = client.execRequest(request)
+		if response != nil {
+			defer response.Body.Close()
+		}
 		if err != nil {
 			return err
 		}
@@ -221,10 +239,13 @@ func (client *Client) doWithResult(request *http.Request, result interface{}) er
 	return err
 }
 
-//do executes the StormpathRequest without expecting a response body as a result,
+//do executes the StormpathRequest without expecting a response body as a result, the response body is closed,
 //it returns an error if any occurred while executing the request
 func (client *Client) do(request *http.Request) error {
-	_, err := client.execRequest(request)
+	response, err := client.execRequest(request)
+	if response != nil {
+		response.Body.Close()
+	}
 	return err
 }
 
@@ -233,12 +254,12 @@ func (client *Client) execRequest(req *http.Request) (*http.Response, error) {
 
 	var dump []byte
 	dump, _ = httputil.DumpRequest(req, true)
-	ae.Debugf(client.ctx, "Stormpath request\n%s", dump)
+	ae.Debugf(client.ctx, "Stormpath request\n%s", redactSecrets(dump))
 
 	resp, err := client.httpClient.Do(req)
 
 	dump, _ = httputil.DumpResponse(resp, true)
-	ae.Debugf(client.ctx, "Stormpath response\n%s", dump)
+	ae.Debugf(client.ctx, "Stormpath response\n%s", redactSecrets(dump))
 
 	return resp, client.handleResponseError(resp, err)
 }
@@ -104,6 +104,7 @@ var _ = BeforeSuite(func() {
 	}
 
 	app = newTestApplication()
+	app.AuthorizedCallbackURIs = []string{"http://localhost:8080"}
 
 	err = tenant.CreateApplication(ctx, app)
 	if err != nil {
@@ -8,10 +8,11 @@ import (
 //Tenant represents a Stormpath tenant see http://docs.stormpath.com/rest/product-guide/#tenants
 type Tenant struct {
 	customDataAwareResource
-	Name         string       `json:"name"`
-	Key          string       `json:"key"`
-	Applications Applications `json:"applications"`
-	Directories  Directories  `json:"directories"`
+	Name          string        `json:"name"`
+	Key           string        `json:"key"`
+	Applications  Applications  `json:"applications"`
+	Directories   Directories   `json:"directories"`
+	Organizations Organizations `json:"organizations"`
 }
 
 //CurrentTenant returns the current tenant see http://docs.stormpath.com/rest/product-guide/#retrieve-the-current-tenant
@@ -69,3 +70,21 @@ func (tenant *Tenant) GetDirectories(ctx context.Context, criteria Criteria) (*D
 
 	return directories, err
 }
+
+//CreateOrganization creates a new organization for the given tenant
+//
+//See: http://docs.stormpath.com/rest/product-guide/#tenant-organizations
+func (tenant *Tenant) CreateOrganization(ctx context.Context, org *Organization) error {
+	return getClient(ctx).post(buildRelativeURL("organizations"), org, org)
+}
+
+//GetOrganizations returns all the organizations for the given tenant
+//
+//See: http://docs.stormpath.com/rest/product-guide/#tenant-organizations
+func (tenant *Tenant) GetOrganizations(ctx context.Context, criteria Criteria) (*Organizations, error) {
+	organizations := &Organizations{}
+
+	err := getClient(ctx).get(buildAbsoluteURL(tenant.Organizations.Href, criteria.ToQueryString()), emptyPayload(), organizations)
+
+	return organizations, err
+}
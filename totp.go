@@ -0,0 +1,41 @@
+package stormpath
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+//TOTPPeriod is the time step of the codes generated by GenerateTOTPCode
+const TOTPPeriod = 30 * time.Second
+
+//GenerateTOTPCode generates the 6 digits RFC 6238 TOTP code (HMAC-SHA1, 30 seconds step) for the given
+//base32 encoded secret at time t, the same code an authenticator app would display for a
+//Google Authenticator factor secret
+func GenerateTOTPCode(secret string, t time.Time) (string, error) {
+	secret = strings.ToUpper(strings.Replace(secret, " ", "", -1))
+	if m := len(secret) % 8; m != 0 {
+		secret += strings.Repeat("=", 8-m)
+	}
+
+	key, err := base32.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", err
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(t.Unix()/int64(TOTPPeriod/time.Second)))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%06d", code%1000000), nil
+}
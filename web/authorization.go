@@ -0,0 +1,262 @@
+package stormpathweb
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/sappenin/stormpath-sdk-go"
+	"golang.org/x/net/context"
+)
+
+//GroupMatch selects whether RequireGroups accepts an account in any or in all the given groups
+type GroupMatch int
+
+const (
+	//AnyGroup requires the account to be in at least one of the groups
+	AnyGroup GroupMatch = iota
+	//AllGroups requires the account to be in every group
+	AllGroups
+)
+
+//authorizationGroupsPageSize is the page size used to list the account groups, 100 is the Stormpath maximum
+const authorizationGroupsPageSize = 100
+
+//authorizationAccountCriteria expands the first page of the account groups and the account custom data
+func authorizationAccountCriteria() stormpath.Criteria {
+	return stormpath.MakeAccountCriteria().
+		WithGroups(stormpath.PageRequest{Limit: authorizationGroupsPageSize}).
+		WithCustomData()
+}
+
+//CustomDataPredicate returns true if an account with the given custom data is authorized
+type CustomDataPredicate func(customData stormpath.CustomData) bool
+
+//DefaultForbiddenHandler responds with a 403 Forbidden, as a JSON error to JSON clients
+func DefaultForbiddenHandler(w http.ResponseWriter, r *http.Request) {
+	if wantsJSON(r) {
+		writeJSONError(w, http.StatusForbidden, http.StatusText(http.StatusForbidden))
+		return
+	}
+	http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+}
+
+//Authorization creates the authorization middlewares, they must be chained after a middleware that stores the
+//account in the request context such as AccountHandler or TokenAuthenticationHandler, requests without an account
+//are forbidden.
+//
+//The account groups and custom data are fetched with a single request expanding both the first time an
+//authorization middleware needs them in a request, and are then shared by the chained authorization middlewares
+//of that request. The expanded account is cached by the client cache if there is one, see InvalidateAuthorization
+//for the group membership and custom data changes to apply before it expires.
+//
+//Groups are given by href. Group names are only unique within a directory, so names can only be given with
+//GroupDirectoryHref and only match the groups of that directory.
+type Authorization struct {
+	GroupDirectoryHref string
+	//ForbiddenHandler defaults to DefaultForbiddenHandler
+	ForbiddenHandler http.Handler
+	//ErrorHandler defaults to DefaultErrorHandler
+	ErrorHandler ErrorHandlerFunc
+}
+
+//RequireGroups returns a middleware that only lets through the accounts in any or all of the given groups,
+//disabled groups are ignored. It panics if a group isn't an href and GroupDirectoryHref isn't set.
+func (a Authorization) RequireGroups(match GroupMatch, groups ...string) func(http.Handler) http.Handler {
+	if a.GroupDirectoryHref == "" {
+		for _, group := range groups {
+			if !isHref(group) {
+				panic("stormpathweb: group " + strconv.Quote(group) + " is not an href, group names require a GroupDirectoryHref")
+			}
+		}
+	}
+
+	return a.require(func(ctx context.Context, data *authorizationData) (bool, error) {
+		accountGroups, err := data.groups(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		found := 0
+		for _, group := range groups {
+			if a.isInGroup(accountGroups, group) {
+				if match == AnyGroup {
+					return true, nil
+				}
+				found++
+			}
+		}
+		return match == AllGroups && found == len(groups), nil
+	})
+}
+
+//RequireCustomData returns a middleware that only lets through the accounts whose custom data satisfy
+//the given predicate, e.g. func(customData stormpath.CustomData) bool { return customData["plan"] == "pro" }
+func (a Authorization) RequireCustomData(predicate CustomDataPredicate) func(http.Handler) http.Handler {
+	return a.require(func(ctx context.Context, data *authorizationData) (bool, error) {
+		customData, err := data.customData(ctx)
+		if err != nil {
+			return false, err
+		}
+		return predicate(customData), nil
+	})
+}
+
+//RequireGroups is a shortcut for Authorization{}.RequireGroups, the groups must be given by href.
+//It panics if a group isn't an href.
+func RequireGroups(match GroupMatch, groups ...string) func(http.Handler) http.Handler {
+	return Authorization{}.RequireGroups(match, groups...)
+}
+
+//RequireCustomData is a shortcut for Authorization{}.RequireCustomData
+func RequireCustomData(predicate CustomDataPredicate) func(http.Handler) http.Handler {
+	return Authorization{}.RequireCustomData(predicate)
+}
+
+//InvalidateAuthorization evicts the groups and custom data of the account from the client cache, it must be called
+//once the group memberships or the custom data of the account are changed for the authorization middlewares
+//to apply the changes before the cache expires. Other instances with their own cache only see them once it expires.
+func InvalidateAuthorization(accountHref string) {
+	stormpath.EvictCache(accountHref, authorizationAccountCriteria())
+}
+
+func (a Authorization) require(authorized func(ctx context.Context, data *authorizationData) (bool, error)) func(http.Handler) http.Handler {
+	forbiddenHandler := a.ForbiddenHandler
+	if forbiddenHandler == nil {
+		forbiddenHandler = http.HandlerFunc(DefaultForbiddenHandler)
+	}
+	errorHandler := a.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = DefaultErrorHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			account := GetCurrentAccount(r)
+			if account == nil {
+				forbiddenHandler.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := ContextFunc(r)
+
+			data, ok := ctx.Value(authorizationContextKey).(*authorizationData)
+			if !ok || data.account.Href != account.Href {
+				data = &authorizationData{account: account}
+				ctx = context.WithValue(ctx, authorizationContextKey, data)
+				r = r.WithContext(ctx)
+			}
+
+			ok, err := authorized(ctx, data)
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+			if !ok {
+				forbiddenHandler.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+//isHref returns true if the group is given by an absolute URL
+func isHref(group string) bool {
+	u, err := url.Parse(group)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+//isInGroup returns true if one of the enabled groups has the given href, or the given name within GroupDirectoryHref
+func (a Authorization) isInGroup(groups []stormpath.Group, group string) bool {
+	for _, g := range groups {
+		if g.Status == stormpath.Disabled {
+			continue
+		}
+		if g.Href == group {
+			return true
+		}
+		if a.GroupDirectoryHref != "" && g.Name == group && g.Directory != nil && g.Directory.Href == a.GroupDirectoryHref {
+			return true
+		}
+	}
+	return false
+}
+
+//authorizationData holds the expanded account of the request account once it has been fetched
+type authorizationData struct {
+	account       *stormpath.Account
+	expanded      *stormpath.Account
+	accountGroups []stormpath.Group
+}
+
+//expand fetches the account with its first page of groups and its custom data
+func (data *authorizationData) expand(ctx context.Context) (*stormpath.Account, error) {
+	if data.expanded != nil {
+		return data.expanded, nil
+	}
+
+	expanded, err := stormpath.GetAccount(ctx, data.account.Href, authorizationAccountCriteria())
+	if err != nil {
+		return nil, err
+	}
+
+	data.expanded = expanded
+	return expanded, nil
+}
+
+//groups returns the account groups, the memberships are only listed if the account has more groups than
+//the expanded page holds, collections are never cached by the client
+func (data *authorizationData) groups(ctx context.Context) ([]stormpath.Group, error) {
+	if data.accountGroups != nil {
+		return data.accountGroups, nil
+	}
+
+	account, err := data.expand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []stormpath.Group{}
+	if account.Groups != nil {
+		groups = append(groups, account.Groups.Items...)
+	}
+
+	if len(groups) >= authorizationGroupsPageSize {
+		groups = []stormpath.Group{}
+		for offset := 0; ; offset += authorizationGroupsPageSize {
+			memberships, err := account.GetGroupMemberships(
+				ctx,
+				stormpath.MakeGroupMemershipCriteria().WithGroup().Offset(offset).Limit(authorizationGroupsPageSize),
+			)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, membership := range memberships.Items {
+				groups = append(groups, membership.Group)
+			}
+
+			if len(memberships.Items) < authorizationGroupsPageSize {
+				break
+			}
+		}
+	}
+
+	data.accountGroups = groups
+	return groups, nil
+}
+
+//customData returns the expanded account custom data
+func (data *authorizationData) customData(ctx context.Context) (stormpath.CustomData, error) {
+	account, err := data.expand(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if account.CustomData == nil {
+		return stormpath.CustomData{}, nil
+	}
+	return *account.CustomData, nil
+}
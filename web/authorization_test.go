@@ -0,0 +1,170 @@
+package stormpathweb_test
+
+import (
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+//withAccount stores the account in the request context like AccountHandler does
+func withAccount(r *http.Request, account *stormpath.Account) *http.Request {
+	return r.WithContext(WithAccount(r.Context(), account))
+}
+
+func newTestGroupWithAccount(account *stormpath.Account) *stormpath.Group {
+	group := stormpath.NewGroup("group-" + randomName())
+	app.CreateGroup(ctx, group)
+	account.AddToGroup(ctx, group)
+	return group
+}
+
+var _ = Describe("Authorization", func() {
+	var called bool
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	BeforeEach(func() {
+		called = false
+	})
+
+	Describe("without an account", func() {
+		It("should respond with a 403 Forbidden", func() {
+			w := serve(RequireGroups(AnyGroup, app.Href)(next), newRequest("GET", "/admin", nil))
+
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Header().Get("Content-Type")).To(HavePrefix("text/plain"))
+		})
+
+		It("should respond with a JSON error to JSON clients", func() {
+			r := newRequest("GET", "/admin", nil)
+			r.Header.Set("Accept", stormpath.ApplicationJson)
+
+			w := serve(RequireCustomData(func(stormpath.CustomData) bool { return true })(next), r)
+
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+			Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+		})
+
+		It("should use the forbidden handler", func() {
+			authorization := Authorization{ForbiddenHandler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "/login", http.StatusFound)
+			})}
+
+			w := serve(authorization.RequireGroups(AllGroups, app.Href)(next), newRequest("GET", "/admin", nil))
+
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusFound))
+			Expect(w.Header().Get("Location")).To(Equal("/login"))
+		})
+	})
+
+	Describe("RequireGroups", func() {
+		It("should match the groups by href", func() {
+			account := newTestAccount()
+			app.RegisterAccount(ctx, account)
+			group := newTestGroupWithAccount(account)
+			other := newTestGroupWithAccount(newTestAccount())
+
+			serve(RequireGroups(AnyGroup, other.Href, group.Href)(next), withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeTrue())
+
+			called = false
+			w := serve(RequireGroups(AllGroups, other.Href, group.Href)(next), withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should only match the group names of the group directory", func() {
+			account := newTestAccount()
+			app.RegisterAccount(ctx, account)
+			group := newTestGroupWithAccount(account)
+
+			serve(Authorization{GroupDirectoryHref: group.Directory.Href + "other"}.RequireGroups(AnyGroup, group.Name)(next), withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeFalse())
+
+			serve(Authorization{GroupDirectoryHref: group.Directory.Href}.RequireGroups(AnyGroup, group.Name)(next), withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeTrue())
+		})
+
+		It("should panic on group names without a group directory", func() {
+			Expect(func() { RequireGroups(AnyGroup, "admins") }).To(Panic())
+			Expect(func() { Authorization{}.RequireGroups(AllGroups, app.Href, "/groups/admins") }).To(Panic())
+			Expect(func() { Authorization{GroupDirectoryHref: app.Href}.RequireGroups(AnyGroup, "admins") }).NotTo(Panic())
+		})
+
+		It("should ignore the disabled groups", func() {
+			account := newTestAccount()
+			app.RegisterAccount(ctx, account)
+			group := newTestGroupWithAccount(account)
+			group.Status = stormpath.Disabled
+			group.Update(ctx)
+
+			w := serve(RequireGroups(AnyGroup, group.Href)(next), withAccount(newRequest("GET", "/admin", nil), account))
+
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("should apply the membership changes to the next request", func() {
+			account := newTestAccount()
+			app.RegisterAccount(ctx, account)
+			group := newTestGroupWithAccount(account)
+			handler := RequireGroups(AnyGroup, group.Href)(next)
+
+			serve(handler, withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeTrue())
+
+			account.RemoveFromGroup(ctx, group)
+			InvalidateAuthorization(account.Href)
+			called = false
+
+			serve(handler, withAccount(newRequest("GET", "/admin", nil), account))
+			Expect(called).To(BeFalse())
+		})
+	})
+
+	Describe("RequireCustomData", func() {
+		isPro := func(customData stormpath.CustomData) bool {
+			return customData["plan"] == "pro"
+		}
+
+		It("should check the account custom data", func() {
+			account := newTestAccount()
+			app.RegisterAccount(ctx, account)
+			handler := RequireCustomData(isPro)(next)
+
+			w := serve(handler, withAccount(newRequest("GET", "/pro", nil), account))
+			Expect(called).To(BeFalse())
+			Expect(w.Code).To(Equal(http.StatusForbidden))
+
+			account.UpdateCustomData(ctx, stormpath.CustomData{"plan": "pro"})
+			InvalidateAuthorization(account.Href)
+
+			serve(handler, withAccount(newRequest("GET", "/pro", nil), account))
+			Expect(called).To(BeTrue())
+		})
+
+		It("should call the error handler if the custom data can't be fetched", func() {
+			var handledErr error
+			authorization := Authorization{ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+				handledErr = err
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}}
+			deleted := &stormpath.Account{}
+			deleted.Href = account.Href + "deleted"
+
+			w := serve(authorization.RequireCustomData(isPro)(next), withAccount(newRequest("GET", "/pro", nil), deleted))
+
+			Expect(called).To(BeFalse())
+			Expect(handledErr).To(HaveOccurred())
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package stormpathweb
+
+import (
+	"net/http"
+
+	"github.com/sappenin/stormpath-sdk-go"
+	"golang.org/x/net/context"
+)
+
+//contextKey is the type of the request context keys of this package, it prevents collisions with other packages
+type contextKey int
+
+const (
+	applicationContextKey contextKey = iota
+	accountContextKey
+	authorizationContextKey
+)
+
+//ContextFunc returns the context used for the Stormpath API calls made while handling a request, it defaults
+//to the request context. On App Engine it must be set to a function returning appengine.NewContext(r).
+//The returned context must be derived from the request context, the middlewares store the account in it.
+var ContextFunc = func(r *http.Request) context.Context {
+	return r.Context()
+}
+
+//WithApplication returns a copy of ctx holding the given application
+func WithApplication(ctx context.Context, app *stormpath.Application) context.Context {
+	return context.WithValue(ctx, applicationContextKey, app)
+}
+
+//ApplicationFromContext returns the application stored in the context by ApplicationHandler or nil
+func ApplicationFromContext(ctx context.Context) *stormpath.Application {
+	app, _ := ctx.Value(applicationContextKey).(*stormpath.Application)
+	return app
+}
+
+//WithAccount returns a copy of ctx holding the given account
+func WithAccount(ctx context.Context, account *stormpath.Account) context.Context {
+	return context.WithValue(ctx, accountContextKey, account)
+}
+
+//AccountFromContext returns the current account stored in the context by AccountHandler or nil
+func AccountFromContext(ctx context.Context) *stormpath.Account {
+	account, _ := ctx.Value(accountContextKey).(*stormpath.Account)
+	return account
+}
@@ -89,9 +89,7 @@ func main() {
 		negroni.Wrap(authRouter),
 	))
 
-	n.UseHandler(applicationMiddleware())
-	n.UseHandler(accountMiddleware())
-	n.UseHandler(router)
+	n.UseHandler(applicationHandler(accountHandler(router)))
 
 	n.Run(":9999")
 
@@ -112,28 +110,23 @@ func unauthorizedHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/?unauthorize", http.StatusFound)
 }
 
-func accountMiddleware() stormpathweb.AccountMiddleware {
-	return stormpathweb.AccountMiddleware{
-		SessionStore: store,
-		SessionName:  sessionName,
-	}
+func accountHandler(next http.Handler) http.Handler {
+	return stormpathweb.AccountHandler(store, sessionName)(next)
 }
 
-func applicationMiddleware() stormpathweb.ApplicationMiddleware {
-	return stormpathweb.ApplicationMiddleware{
-		ApplicationHref: os.Getenv("APPLICATION_HREF"),
-	}
+func applicationHandler(next http.Handler) http.Handler {
+	return stormpathweb.ApplicationHandler(os.Getenv("APPLICATION_HREF"), nil)(next)
 }
 
 func loginHandler() stormpathweb.ContextHandlerFunc {
 	return stormpathweb.IDSiteLoginHandler{
-		Options: map[string]string{"callbackURI": "/callback"},
+		Options: stormpath.IDSiteOptions{CallbackURI: "/callback"},
 	}.ServeHTTP
 }
 
 func logoutHandler() stormpathweb.ContextHandlerFunc {
 	return stormpathweb.IDSiteLogoutHandler{
-		Options: map[string]string{"callbackURI": "/callback"},
+		Options: stormpath.IDSiteOptions{CallbackURI: "/callback"},
 	}.ServeHTTP
 }
 
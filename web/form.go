@@ -0,0 +1,250 @@
+package stormpathweb
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//csrfTokenKey is the key of the CSRF token in the session
+const csrfTokenKey = "csrf_token"
+
+var (
+	errNoApplication    = errors.New("no Stormpath application in the request context")
+	errInvalidCSRFToken = errors.New("invalid or missing CSRF token")
+)
+
+//FormField describes an input of the login, registration or password forms, Type is the HTML input type
+type FormField struct {
+	Name     string
+	Label    string
+	Type     string
+	Required bool
+}
+
+//FormData is the data given to the form templates. Values holds the submitted values to render them back
+//(passwords are never rendered back), Error the message of a failed submission and Message an informative
+//message, e.g. after a successful registration that requires email verification
+type FormData struct {
+	Action    string
+	Fields    []FormField
+	Values    map[string]string
+	Error     string
+	Errors    []string
+	Message   string
+	CSRFToken string
+}
+
+//SuccessHook is called by the password and verification handlers once the account has been updated, before
+//the default response. An error aborts the request with a 500 Internal Server Error.
+type SuccessHook func(w http.ResponseWriter, r *http.Request, account *stormpath.Account) error
+
+//jsonError is the body of a JSON error response
+type jsonError struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+//wantsJSON returns true if the client sent or expects JSON instead of HTML
+func wantsJSON(r *http.Request) bool {
+	if isJSONRequest(r) {
+		return true
+	}
+
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, stormpath.ApplicationJson) && !strings.Contains(accept, "text/html")
+}
+
+func isJSONRequest(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == stormpath.ApplicationJson
+}
+
+//parseInput reads the submitted values either from a JSON object body or from a form
+func parseInput(r *http.Request) (map[string]string, error) {
+	values := make(map[string]string)
+
+	if isJSONRequest(r) {
+		input := make(map[string]interface{})
+		err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&input)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range input {
+			if s, ok := value.(string); ok {
+				values[name] = s
+			}
+		}
+		return values, nil
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		return nil, err
+	}
+	for name := range r.PostForm {
+		values[name] = r.PostForm.Get(name)
+	}
+
+	return values, nil
+}
+
+//missingFields returns the labels of the required fields without a value
+func missingFields(fields []FormField, values map[string]string) []string {
+	var missing []string
+	for _, field := range fields {
+		if field.Required && strings.TrimSpace(values[field.Name]) == "" {
+			missing = append(missing, field.Label+" is required")
+		}
+	}
+	return missing
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", stormpath.ApplicationJson)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, jsonError{Status: status, Message: message})
+}
+
+//errorStatus maps an error to the HTTP status and the user facing message of the response,
+//Stormpath errors user messages are safe to display, any other error is an internal error
+func errorStatus(err error) (int, string) {
+	if spErr, ok := err.(stormpath.Error); ok && spErr.Status >= 400 && spErr.Status < 500 {
+		return http.StatusBadRequest, spErr.Message
+	}
+	if _, ok := err.(stormpath.PasswordStrengthError); ok {
+		return http.StatusBadRequest, err.Error()
+	}
+	return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+}
+
+//renderForm renders an HTML form template with the given status, password values are never rendered back
+func renderForm(w http.ResponseWriter, tmpl *template.Template, status int, data FormData) {
+	values := make(map[string]string)
+	for _, field := range data.Fields {
+		if field.Type != "password" && data.Values[field.Name] != "" {
+			values[field.Name] = data.Values[field.Name]
+		}
+	}
+	data.Values = values
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	tmpl.Execute(w, data)
+}
+
+//formView renders a form either as JSON, only its fields, or as HTML with a new CSRF token
+type formView struct {
+	SessionStore sessions.Store
+	SessionName  string
+	Template     *template.Template
+}
+
+func (v formView) render(w http.ResponseWriter, r *http.Request, status int, data FormData) {
+	if wantsJSON(r) {
+		writeJSON(w, status, map[string]interface{}{"fields": data.Fields})
+		return
+	}
+
+	token, err := csrfToken(v.SessionStore, v.SessionName, w, r)
+	if err != nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	data.Action = r.URL.RequestURI()
+	data.CSRFToken = token
+
+	renderForm(w, v.Template, status, data)
+}
+
+//fail responds with a JSON error or renders the form again with the error messages
+func (v formView) fail(w http.ResponseWriter, r *http.Request, status int, message string, errors []string, data FormData) {
+	if wantsJSON(r) {
+		if message == "" && len(errors) > 0 {
+			message = errors[0]
+		}
+		writeJSONError(w, status, message)
+		return
+	}
+
+	data.Error = message
+	data.Errors = errors
+	v.render(w, r, status, data)
+}
+
+//csrfToken returns the CSRF token of the session, a new token is generated and saved if there isn't one yet
+func csrfToken(store sessions.Store, sessionName string, w http.ResponseWriter, r *http.Request) (string, error) {
+	session, _ := store.Get(r, sessionName)
+
+	if token, ok := session.Values[csrfTokenKey].(string); ok && token != "" {
+		return token, nil
+	}
+
+	b := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, b)
+	if err != nil {
+		return "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+	session.Values[csrfTokenKey] = token
+
+	return token, session.Save(r, w)
+}
+
+//validCSRFToken checks the submitted CSRF token against the session one. JSON requests are not checked since
+//browsers can't send them cross-origin without a CORS preflight request.
+func validCSRFToken(store sessions.Store, sessionName string, r *http.Request, values map[string]string) bool {
+	if isJSONRequest(r) {
+		return true
+	}
+
+	session, _ := store.Get(r, sessionName)
+	token, ok := session.Values[csrfTokenKey].(string)
+
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(values[csrfTokenKey])) == 1
+}
+
+//saveAccountInSession stores the given account in the session as the current account
+func saveAccountInSession(store sessions.Store, sessionName string, account *stormpath.Account, w http.ResponseWriter, r *http.Request) error {
+	session, _ := store.Get(r, sessionName)
+
+	jsonBody, err := json.Marshal(account)
+	if err != nil {
+		return err
+	}
+
+	session.Values[AccountKey] = jsonBody
+	return session.Save(r, w)
+}
+
+//clearAccountInSession removes the current account from the session
+func clearAccountInSession(store sessions.Store, sessionName string, w http.ResponseWriter, r *http.Request) error {
+	session, _ := store.Get(r, sessionName)
+
+	session.Values[AccountKey] = nil
+	return session.Save(r, w)
+}
+
+//redirectURI returns the safe "next" query parameter if any or the given default URI
+func redirectURI(r *http.Request, defaultURI string) string {
+	if next := r.URL.Query().Get("next"); next != "" && stormpath.IsSafeRedirectURI(next) {
+		return next
+	}
+	return defaultURI
+}
@@ -1,13 +1,13 @@
 package stormpathweb
 
 import (
-	"encoding/json"
 	"net/http"
 	"github.com/gorilla/sessions"
 	"github.com/sappenin/stormpath-sdk-go"
 	"google.golang.org/appengine/log"
 	"fmt"
 	"net/url"
+	"strings"
 	"google.golang.org/appengine"
 	"github.com/nu7hatch/gouuid"
 	"golang.org/x/net/context"
@@ -37,52 +37,84 @@ func (f ContextHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-//IDSiteLoginHandler is an http.Handler for Strompath's IDSite login
+//IDSiteLoginHandler is an http.Handler for Strompath's IDSite login. With a StateCodec the Options.State and
+//the request next parameter, if it is a local path, are signed into the ID Site state and given back to
+//the IDSiteAuthCallbackHandler using the same StateCodec.
 type IDSiteLoginHandler struct {
-	Options map[string]string
+	Options    stormpath.IDSiteOptions
+	StateCodec *stormpath.StateCodec
 }
 
-//IDSiteLogoutHandler is an http.Handler for Strompath's IDSite logout
+//IDSiteLogoutHandler is an http.Handler for Strompath's IDSite logout, see IDSiteLoginHandler for the StateCodec
 type IDSiteLogoutHandler struct {
-	Options map[string]string
+	Options    stormpath.IDSiteOptions
+	StateCodec *stormpath.StateCodec
 }
 
 //ServeHTTP implements the http.Handler interface for IDSiteLoginHandler type and ContextHandlerFunc to support App Engine Contexts
 func (h IDSiteLoginHandler) ServeHTTP(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	idSiteURLHandler(ctx, w, r, ensureOption("logout", "", h.Options))
+	options := h.Options
+	options.Logout = false
+	idSiteURLHandler(ctx, w, r, options, h.StateCodec)
 }
 
 // Implement ContextHandlerFunc for IDSiteLogoutHandler
 func (h IDSiteLogoutHandler) ServeHTTP(ctx context.Context, w http.ResponseWriter, r *http.Request) {
-	idSiteURLHandler(ctx, w, r, ensureOption("logout", "true", h.Options))
+	options := h.Options
+	options.Logout = true
+	idSiteURLHandler(ctx, w, r, options, h.StateCodec)
 }
 
-func idSiteURLHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, options map[string]string) {
-	if options["callbackURI"][0] == '/' {
-		u, _ := url.Parse(r.Header.Get("Referer"))
-		callbackURL := fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, options["callbackURI"])
-		options["callbackURI"] = callbackURL
+//idSiteURLHandler redirects to ID Site, a relative callback URI is resolved against the request Referer
+func idSiteURLHandler(ctx context.Context, w http.ResponseWriter, r *http.Request, options stormpath.IDSiteOptions, stateCodec *stormpath.StateCodec) {
+	if stateCodec != nil {
+		state, err := stateCodec.Encode(redirectURI(r, ""), options.State)
+		if err != nil {
+			log.Errorf(ctx, "IDSite state %s", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		options.State = state
 	}
 
-	idSiteURL, _ := GetApplication(r).CreateIDSiteURL(ctx, options)
-	http.Redirect(w, r, idSiteURL, http.StatusFound)
-}
+	if strings.HasPrefix(options.CallbackURI, "/") {
+		u, _ := url.Parse(r.Header.Get("Referer"))
+		options.CallbackURI = fmt.Sprintf("%s://%s%s", u.Scheme, u.Host, options.CallbackURI)
+	}
 
-func ensureOption(key string, value string, options map[string]string) map[string]string {
-	if options == nil {
-		options = make(map[string]string)
+	idSiteURL, err := GetApplication(r).CreateIDSiteURLWithOptions(ctx, options)
+	if err != nil {
+		log.Debugf(ctx, "IDSite %s", err)
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
 	}
-	options[key] = value
-	return options
+
+	http.Redirect(w, r, idSiteURL, http.StatusFound)
 }
 
-//IDSiteAuthCallbackHandler is an http.Handler for the ID Site callback
+//IDSiteCallbackHook is called by IDSiteAuthCallbackHandler once the session has been updated for a callback status,
+//it takes over the response instead of the default redirect
+type IDSiteCallbackHook func(ctx context.Context, w http.ResponseWriter, r *http.Request, result *stormpath.IDSiteCallbackResult)
+
+//IDSiteAuthCallbackHandler is an http.Handler for the ID Site callback, each callback status redirects to its
+//own URI or calls its own hook if set. RegisterRedirectURI defaults to LoginRedirectURI.
+//CallbackOptions configures the callback validation, e.g. a MemcacheNonceStore when running several instances.
+//
+//With a StateCodec, the one of the IDSiteLoginHandler and IDSiteLogoutHandler, a state that isn't valid is
+//an error, the hooks get the decoded IDSiteOptions.State as result.State and the return to URI of the state
+//takes precedence over the redirect URIs.
 type IDSiteAuthCallbackHandler struct {
-	SessionStore      sessions.Store
-	SessionName       string
-	LoginRedirectURI  string
-	LogoutRedirectURI string
-	ErrorHandler      http.Handler
+	SessionStore        sessions.Store
+	SessionName         string
+	CallbackOptions     stormpath.IDSiteCallbackOptions
+	StateCodec          *stormpath.StateCodec
+	LoginRedirectURI    string
+	RegisterRedirectURI string
+	LogoutRedirectURI   string
+	AuthenticatedHook   IDSiteCallbackHook
+	RegisteredHook      IDSiteCallbackHook
+	LogoutHook          IDSiteCallbackHook
+	ErrorHandler        http.Handler
 }
 
 //ServeHTTP implements the http.Handler interface for the IDSiteAuthCallbackHandler type and ContextHandlerFunc to support App Engine Contexts
@@ -93,10 +125,11 @@ func (h IDSiteAuthCallbackHandler) ServeHTTP(ctx context.Context, w http.Respons
 		log.Debugf(ctx, "No StormPath Application found in Context.  Clearing Account Session!")
 		h.clearAccountInSession(w, r)
 		http.Redirect(w, r, "/", http.StatusFound)
+		return
 	}
 
 	log.Debugf(ctx, "StormPath Application was found in Context: %#v", app)
-	result, err := app.HandleIDSiteCallback(ctx, r.URL.String())
+	result, err := app.HandleIDSiteCallback(ctx, r.URL.String(), h.CallbackOptions)
 
 	// TODO: Make these nicer.  If there's a JWT error of some sort, we should redirect the User to the Oops page.
 	if err != nil {
@@ -105,33 +138,60 @@ func (h IDSiteAuthCallbackHandler) ServeHTTP(ctx context.Context, w http.Respons
 		return
 	}
 
-	if result.Status == "AUTHENTICATED" {
-		//Login succesful
+	returnTo := ""
+	if h.StateCodec != nil {
+		state := ""
+		returnTo, err = h.StateCodec.Decode(result.State, &state)
+		if err != nil {
+			log.Debugf(ctx, "IDSite state %s", err)
+			h.ErrorHandler.ServeHTTP(w, r)
+			return
+		}
+		result.State = state
+	}
+
+	var hook IDSiteCallbackHook
+	var redirectURI string
+
+	switch result.Status {
+	case stormpath.IDSiteAuthenticated:
 		log.Debugf(ctx, "Login Successful!  Storing Account in Session.")
 		h.storeAccountInSession(result.Account, w, r)
-		http.Redirect(w, r, h.LoginRedirectURI, http.StatusFound)
-	} else {
-		//Logout
+		hook, redirectURI = h.AuthenticatedHook, h.LoginRedirectURI
+	case stormpath.IDSiteRegistered:
+		//The account is only given if it doesn't need to verify its email first
+		if result.Account != nil {
+			log.Debugf(ctx, "Registration Successful!  Storing Account in Session.")
+			h.storeAccountInSession(result.Account, w, r)
+		}
+		hook, redirectURI = h.RegisteredHook, h.RegisterRedirectURI
+		if redirectURI == "" {
+			redirectURI = h.LoginRedirectURI
+		}
+	case stormpath.IDSiteLogout:
 		log.Debugf(ctx, "Logout Successful!  Clearing Account from Session.")
 		h.clearAccountInSession(w, r)
-		http.Redirect(w, r, h.LogoutRedirectURI, http.StatusFound)
+		hook, redirectURI = h.LogoutHook, h.LogoutRedirectURI
+	}
+
+	if hook != nil {
+		hook(ctx, w, r, result)
+		return
 	}
+
+	if returnTo != "" {
+		redirectURI = returnTo
+	}
+
+	http.Redirect(w, r, redirectURI, http.StatusFound)
 }
 
 //StoreAccountInSession stores a given account in the session as the current account
 func (h IDSiteAuthCallbackHandler) storeAccountInSession(account *stormpath.Account, w http.ResponseWriter, r *http.Request) {
-	session, _ := h.SessionStore.Get(r, h.SessionName)
-
-	jsonBody, _ := json.Marshal(account)
-
-	session.Values[AccountKey] = jsonBody
-	session.Save(r, w)
+	saveAccountInSession(h.SessionStore, h.SessionName, account, w, r)
 }
 
 //ClearAccountInSession removes the current account form the session
 func (h IDSiteAuthCallbackHandler) clearAccountInSession(w http.ResponseWriter, r *http.Request) {
-	session, _ := h.SessionStore.Get(r, h.SessionName)
-
-	session.Values[AccountKey] = nil
-	session.Save(r, w)
+	clearAccountInSession(h.SessionStore, h.SessionName, w, r)
 }
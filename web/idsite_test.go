@@ -0,0 +1,269 @@
+package stormpathweb_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+	"golang.org/x/net/context"
+)
+
+//serveContext runs the request through an App Engine context handler with the test context
+func serveContext(handler ContextHandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler(ctx, w, r)
+	return w
+}
+
+//newIDSiteCallbackRequest returns an ID Site callback request with a jwtResponse signed like ID Site does,
+//the claims override the default ones of an authenticated response
+func newIDSiteCallbackRequest(claims map[string]interface{}) *http.Request {
+	token := jwt.New(jwt.SigningMethodHS256)
+	token.Claims["aud"] = cred.ID
+	token.Claims["iss"] = "https://id.example.com"
+	token.Claims["exp"] = time.Now().Add(time.Minute).Unix()
+	token.Claims["irt"] = randomName()
+	token.Claims["status"] = string(stormpath.IDSiteAuthenticated)
+	for name, value := range claims {
+		token.Claims[name] = value
+	}
+	jwtResponse, _ := token.SignedString([]byte(cred.Secret))
+
+	return newRequest("GET", "/callback?jwtResponse="+url.QueryEscape(jwtResponse), nil)
+}
+
+//idSiteState returns the state of the jwtRequest of an ID Site redirect response
+func idSiteState(w *httptest.ResponseRecorder) string {
+	u, _ := url.Parse(w.Header().Get("Location"))
+	token, err := jwt.Parse(u.Query().Get("jwtRequest"), func(token *jwt.Token) (interface{}, error) {
+		return []byte(cred.Secret), nil
+	})
+	Expect(err).NotTo(HaveOccurred())
+	state, _ := token.Claims["state"].(string)
+	return state
+}
+
+var stateCodec = &stormpath.StateCodec{SigningKey: []byte("0123456789abcdef0123456789abcdef")}
+
+var _ = Describe("IDSiteLoginHandler", func() {
+	options := stormpath.IDSiteOptions{CallbackURI: "http://localhost:8080/callback", State: "data"}
+
+	It("should sign the next parameter and the state into the ID Site state", func() {
+		handler := IDSiteLoginHandler{Options: options, StateCodec: stateCodec}
+
+		w := serveContext(handler.ServeHTTP, newRequest("GET", "/login?next=%2Fdashboard%3Fx%3D1", nil))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		var data string
+		returnTo, err := stateCodec.Decode(idSiteState(w), &data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnTo).To(Equal("/dashboard?x=1"))
+		Expect(data).To(Equal("data"))
+	})
+
+	It("should not sign an unsafe next parameter", func() {
+		handler := IDSiteLoginHandler{Options: options, StateCodec: stateCodec}
+
+		for _, next := range []string{"https://evil.com", "//evil.com", "/\\evil.com"} {
+			w := serveContext(handler.ServeHTTP, newRequest("GET", "/login?next="+url.QueryEscape(next), nil))
+
+			Expect(w.Code).To(Equal(http.StatusFound))
+			returnTo, err := stateCodec.Decode(idSiteState(w), nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(returnTo).To(BeEmpty(), next)
+		}
+	})
+
+	It("should keep the state as is without a StateCodec", func() {
+		w := serveContext(IDSiteLoginHandler{Options: options}.ServeHTTP, newRequest("GET", "/login?next=%2Fdashboard", nil))
+
+		Expect(idSiteState(w)).To(Equal("data"))
+	})
+})
+
+var _ = Describe("IDSiteLogoutHandler", func() {
+	It("should sign the next parameter into the ID Site logout state", func() {
+		handler := IDSiteLogoutHandler{Options: stormpath.IDSiteOptions{CallbackURI: "http://localhost:8080/callback"}, StateCodec: stateCodec}
+
+		w := serveContext(handler.ServeHTTP, newRequest("GET", "/logout?next=%2Fbye", nil))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(ContainSubstring("/sso/logout?"))
+		returnTo, err := stateCodec.Decode(idSiteState(w), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(returnTo).To(Equal("/bye"))
+	})
+})
+
+var _ = Describe("IDSiteAuthCallbackHandler", func() {
+	var results map[stormpath.IDSiteStatus]*stormpath.IDSiteCallbackResult
+
+	hook := func(ctx context.Context, w http.ResponseWriter, r *http.Request, result *stormpath.IDSiteCallbackResult) {
+		results[result.Status] = result
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	errorHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "ID Site error", http.StatusBadRequest)
+	})
+
+	handler := IDSiteAuthCallbackHandler{
+		SessionStore:      sessionStore,
+		SessionName:       sessionName,
+		LoginRedirectURI:  "/home",
+		LogoutRedirectURI: "/bye",
+		ErrorHandler:      errorHandler,
+	}
+
+	BeforeEach(func() {
+		results = map[stormpath.IDSiteStatus]*stormpath.IDSiteCallbackResult{}
+	})
+
+	It("should store an authenticated account in the session and redirect to the login redirect URI", func() {
+		w := serveContext(handler.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"sub": account.Href}))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/home"))
+		Expect(sessionAccount(w).Href).To(Equal(account.Href))
+	})
+
+	It("should redirect a registration to the register redirect URI or the login one", func() {
+		registered := map[string]interface{}{"sub": account.Href, "status": string(stormpath.IDSiteRegistered)}
+
+		w := serveContext(handler.ServeHTTP, newIDSiteCallbackRequest(registered))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/home"))
+		Expect(sessionAccount(w).Href).To(Equal(account.Href))
+
+		h := handler
+		h.RegisterRedirectURI = "/welcome"
+		w = serveContext(h.ServeHTTP, newIDSiteCallbackRequest(registered))
+
+		Expect(w.Header().Get("Location")).To(Equal("/welcome"))
+	})
+
+	It("should not store a registered account that has to verify its email", func() {
+		w := serveContext(handler.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"status": string(stormpath.IDSiteRegistered)}))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(sessionAccount(w)).To(BeNil())
+	})
+
+	It("should clear the session and redirect to the logout redirect URI on logout", func() {
+		login := serveContext(handler.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"sub": account.Href}))
+		Expect(sessionAccount(login)).NotTo(BeNil())
+
+		r := withCookies(newIDSiteCallbackRequest(map[string]interface{}{"status": string(stormpath.IDSiteLogout)}), login)
+		w := serveContext(handler.ServeHTTP, r)
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/bye"))
+		Expect(sessionAccount(w)).To(BeNil())
+	})
+
+	It("should call the hook of each status with the callback result", func() {
+		h := handler
+		h.AuthenticatedHook = hook
+		h.RegisteredHook = hook
+		h.LogoutHook = hook
+
+		w := serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"sub": account.Href, "state": "authenticated"}))
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(sessionAccount(w).Href).To(Equal(account.Href))
+
+		w = serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{
+			"sub":      account.Href,
+			"status":   string(stormpath.IDSiteRegistered),
+			"isNewSub": true,
+			"org_href": "https://api.stormpath.com/v1/organizations/xxxx",
+		}))
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+
+		w = serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"status": string(stormpath.IDSiteLogout)}))
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+
+		Expect(results).To(HaveLen(3))
+		Expect(results[stormpath.IDSiteAuthenticated].Account.Href).To(Equal(account.Href))
+		Expect(results[stormpath.IDSiteAuthenticated].State).To(Equal("authenticated"))
+		Expect(results[stormpath.IDSiteAuthenticated].IsNew).To(BeFalse())
+		Expect(results[stormpath.IDSiteRegistered].IsNew).To(BeTrue())
+		Expect(results[stormpath.IDSiteRegistered].OrganizationHref).To(Equal("https://api.stormpath.com/v1/organizations/xxxx"))
+		Expect(results[stormpath.IDSiteLogout].Account).To(BeNil())
+	})
+
+	It("should call the error handler for an invalid or replayed response", func() {
+		r := newRequest("GET", "/callback?jwtResponse=invalid", nil)
+
+		w := serveContext(handler.ServeHTTP, r)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("ID Site error"))
+
+		r = newIDSiteCallbackRequest(nil)
+		serveContext(handler.ServeHTTP, r)
+		w = serveContext(handler.ServeHTTP, r)
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+	})
+
+	Describe("with a StateCodec", func() {
+		h := handler
+		h.StateCodec = stateCodec
+
+		//callbackState returns the state of an ID Site redirect of the given handler for the given request
+		callbackState := func(handler ContextHandlerFunc, r *http.Request) string {
+			return idSiteState(serveContext(handler, r))
+		}
+
+		login := IDSiteLoginHandler{Options: stormpath.IDSiteOptions{CallbackURI: "http://localhost:8080/callback", State: "data"}, StateCodec: stateCodec}
+
+		It("should redirect to the next parameter given to the login handler", func() {
+			state := callbackState(login.ServeHTTP, newRequest("GET", "/login?next=%2Fdashboard", nil))
+
+			w := serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"state": state}))
+
+			Expect(w.Code).To(Equal(http.StatusFound))
+			Expect(w.Header().Get("Location")).To(Equal("/dashboard"))
+		})
+
+		It("should redirect to the status redirect URI when the next parameter is unsafe", func() {
+			state := callbackState(login.ServeHTTP, newRequest("GET", "/login?next=%2F%2Fevil.com", nil))
+
+			w := serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"state": state}))
+
+			Expect(w.Code).To(Equal(http.StatusFound))
+			Expect(w.Header().Get("Location")).To(Equal("/home"))
+		})
+
+		It("should give the decoded state to the hooks", func() {
+			withHook := h
+			withHook.AuthenticatedHook = hook
+			state := callbackState(login.ServeHTTP, newRequest("GET", "/login?next=%2Fdashboard", nil))
+
+			w := serveContext(withHook.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"state": state}))
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(results[stormpath.IDSiteAuthenticated].State).To(Equal("data"))
+		})
+
+		It("should call the error handler for a missing, tampered or expired state", func() {
+			state, _ := stateCodec.Encode("/dashboard", "data")
+			expired, _ := stormpath.StateCodec{SigningKey: stateCodec.SigningKey, TTL: -time.Minute}.Encode("/dashboard", "data")
+			otherKey, _ := stormpath.StateCodec{SigningKey: []byte("abcdef0123456789abcdef0123456789")}.Encode("/dashboard", "data")
+
+			for _, invalid := range []string{"", "data", state + "x", expired, otherKey} {
+				w := serveContext(h.ServeHTTP, newIDSiteCallbackRequest(map[string]interface{}{"state": invalid}))
+
+				Expect(w.Code).To(Equal(http.StatusBadRequest), invalid)
+				Expect(w.Header().Get("Location")).To(BeEmpty())
+			}
+		})
+	})
+})
@@ -0,0 +1,188 @@
+package stormpathweb
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//DefaultLoginFields are the inputs of the login form, the login field accepts a username or an email
+var DefaultLoginFields = []FormField{
+	{Name: "login", Label: "Username or Email", Type: "text", Required: true},
+	{Name: "password", Label: "Password", Type: "password", Required: true},
+}
+
+//LoginHandler is an http.Handler that renders the login form on GET and authenticates the account on POST,
+//on success the account is stored in the session and the user is redirected to the safe "next" query parameter
+//or RedirectURI. JSON requests get the authenticated account as a JSON response instead.
+//
+//It requires the application in the request context, see ApplicationHandler.
+type LoginHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultLoginTemplate
+	Template *template.Template
+	//Fields customizes the labels of the login and password inputs, defaults to DefaultLoginFields
+	Fields []FormField
+	//RedirectURI defaults to "/"
+	RedirectURI           string
+	AuthenticationOptions stormpath.AuthenticationOptions
+}
+
+//ServeHTTP implements the http.Handler interface for the LoginHandler type
+func (h LoginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.render(w, r, http.StatusOK, FormData{})
+	case "POST":
+		h.login(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h LoginHandler) login(w http.ResponseWriter, r *http.Request) {
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	values, err := parseInput(r)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, "Invalid request", nil, nil)
+		return
+	}
+
+	if !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		h.fail(w, r, http.StatusForbidden, errInvalidCSRFToken.Error(), values, nil)
+		return
+	}
+
+	if missing := missingFields(h.fields(), values); missing != nil {
+		h.fail(w, r, http.StatusBadRequest, "", values, missing)
+		return
+	}
+
+	account, err := app.AuthenticateAccount(ContextFunc(r), values["login"], values["password"], h.AuthenticationOptions)
+	if err != nil {
+		status, message := errorStatus(err)
+		h.fail(w, r, status, message, values, nil)
+		return
+	}
+
+	err = saveAccountInSession(h.SessionStore, h.SessionName, account, w, r)
+	if err != nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"account": account})
+		return
+	}
+
+	http.Redirect(w, r, redirectURI(r, h.redirectURI()), http.StatusFound)
+}
+
+func (h LoginHandler) fail(w http.ResponseWriter, r *http.Request, status int, message string, values map[string]string, errors []string) {
+	h.view().fail(w, r, status, message, errors, FormData{Fields: h.fields(), Values: values})
+}
+
+func (h LoginHandler) render(w http.ResponseWriter, r *http.Request, status int, data FormData) {
+	data.Fields = h.fields()
+	h.view().render(w, r, status, data)
+}
+
+func (h LoginHandler) view() formView {
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultLoginTemplate
+	}
+	return formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}
+}
+
+func (h LoginHandler) fields() []FormField {
+	if h.Fields == nil {
+		return DefaultLoginFields
+	}
+	return h.Fields
+}
+
+func (h LoginHandler) redirectURI() string {
+	if h.RedirectURI == "" {
+		return "/"
+	}
+	return h.RedirectURI
+}
+
+//LogoutHandler is an http.Handler that renders a logout confirmation form on GET and clears the account
+//from the session on POST, logging out with a GET request would allow any other site to log the user out.
+type LogoutHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultLogoutTemplate
+	Template *template.Template
+	//RedirectURI defaults to "/"
+	RedirectURI string
+}
+
+//ServeHTTP implements the http.Handler interface for the LogoutHandler type
+func (h LogoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.render(w, r, http.StatusOK, FormData{})
+	case "POST":
+		h.logout(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h LogoutHandler) logout(w http.ResponseWriter, r *http.Request) {
+	values, err := parseInput(r)
+	if err != nil || !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		if wantsJSON(r) {
+			writeJSONError(w, http.StatusForbidden, errInvalidCSRFToken.Error())
+			return
+		}
+		h.render(w, r, http.StatusForbidden, FormData{Error: errInvalidCSRFToken.Error()})
+		return
+	}
+
+	err = clearAccountInSession(h.SessionStore, h.SessionName, w, r)
+	if err != nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	redirect := h.RedirectURI
+	if redirect == "" {
+		redirect = "/"
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (h LogoutHandler) render(w http.ResponseWriter, r *http.Request, status int, data FormData) {
+	if wantsJSON(r) {
+		w.Header().Set("Allow", "POST")
+		writeJSONError(w, http.StatusMethodNotAllowed, http.StatusText(http.StatusMethodNotAllowed))
+		return
+	}
+
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultLogoutTemplate
+	}
+
+	formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}.render(w, r, status, data)
+}
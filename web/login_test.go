@@ -0,0 +1,205 @@
+package stormpathweb_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+//sessionAccount returns the account stored in the session set by a response or nil
+func sessionAccount(w *httptest.ResponseRecorder) *stormpath.Account {
+	var current *stormpath.Account
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current = GetCurrentAccount(r)
+	})
+
+	serve(AccountHandler(sessionStore, sessionName)(next), withCookies(newRequest("GET", "/", nil), w))
+	return current
+}
+
+var _ = Describe("LoginHandler", func() {
+	handler := LoginHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should render the login form with a CSRF token", func() {
+		token, w := getCSRFToken(handler, "/login")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(w.Body.String()).To(ContainSubstring(`name="login"`))
+		Expect(token).NotTo(BeEmpty())
+		Expect(responseCookie(w, sessionName)).NotTo(BeNil())
+	})
+
+	It("should return the form fields to JSON clients", func() {
+		r := newRequest("GET", "/login", nil)
+		r.Header.Set("Accept", stormpath.ApplicationJson)
+
+		w := serve(handler, r)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+		Expect(w.Body.String()).To(ContainSubstring(`"fields"`))
+		Expect(responseCookie(w, sessionName)).To(BeNil())
+	})
+
+	It("should reject a form post without a valid CSRF token", func() {
+		_, w := getCSRFToken(handler, "/login")
+		values := url.Values{"login": {account.Email}, "password": {password}}
+
+		for _, token := range []string{"", "invalid"} {
+			values.Set("csrf_token", token)
+			response := serve(handler, withCookies(newFormRequest("POST", "/login", values), w))
+
+			Expect(response.Code).To(Equal(http.StatusForbidden))
+			Expect(response.Body.String()).To(ContainSubstring("invalid or missing CSRF token"))
+		}
+
+		values.Set("csrf_token", "a token without a session")
+		response := serve(handler, newFormRequest("POST", "/login", values))
+		Expect(response.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should render the form again for missing fields", func() {
+		token, w := getCSRFToken(handler, "/login")
+		values := url.Values{"csrf_token": {token}, "login": {"someone"}}
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/login", values), w))
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		Expect(response.Body.String()).To(ContainSubstring("Password is required"))
+		Expect(response.Body.String()).To(ContainSubstring(`value="someone"`))
+	})
+
+	It("should respond with a JSON error to JSON requests", func() {
+		w := serve(handler, newJSONRequest("POST", "/login", map[string]string{"login": "someone"}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+		Expect(w.Body.String()).To(ContainSubstring("Password is required"))
+	})
+
+	It("should log in and redirect to the safe next URI", func() {
+		token, w := getCSRFToken(handler, "/login?next=/app")
+		values := url.Values{"csrf_token": {token}, "login": {account.Email}, "password": {password}}
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/login?next=/app", values), w))
+
+		Expect(response.Code).To(Equal(http.StatusFound))
+		Expect(response.Header().Get("Location")).To(Equal("/app"))
+		Expect(sessionAccount(response).Href).To(Equal(account.Href))
+	})
+
+	It("should not redirect to another host", func() {
+		token, w := getCSRFToken(handler, "/login")
+		values := url.Values{"csrf_token": {token}, "login": {account.Email}, "password": {password}}
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/login?next=//evil.com", values), w))
+
+		Expect(response.Code).To(Equal(http.StatusFound))
+		Expect(response.Header().Get("Location")).To(Equal("/"))
+	})
+
+	It("should return the account to JSON clients", func() {
+		w := serve(handler, newJSONRequest("POST", "/login", map[string]string{"login": account.Email, "password": password}))
+
+		result := map[string]stormpath.Account{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(result["account"].Href).To(Equal(account.Href))
+	})
+
+	It("should reject invalid credentials", func() {
+		w := serve(handler, newJSONRequest("POST", "/login", map[string]string{"login": account.Email, "password": "wrong"}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(sessionAccount(w)).To(BeNil())
+	})
+})
+
+var _ = Describe("RegisterHandler", func() {
+	handler := RegisterHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should reject a form post without a valid CSRF token", func() {
+		values := url.Values{"givenName": {"givenName"}, "surname": {"surname"}, "email": {"someone@test.org"}, "password": {password}}
+
+		w := serve(handler, newFormRequest("POST", "/register", values))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+	})
+
+	It("should require the password confirmation to match", func() {
+		handler := RegisterHandler{
+			SessionStore: sessionStore,
+			SessionName:  sessionName,
+			Fields:       append(DefaultRegisterFields, FormField{Name: "confirmPassword", Label: "Confirm Password", Type: "password"}),
+		}
+		input := map[string]string{"givenName": "givenName", "surname": "surname", "email": "someone@test.org", "password": password, "confirmPassword": "other"}
+
+		w := serve(handler, newJSONRequest("POST", "/register", input))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("Passwords do not match"))
+	})
+
+	It("should register and log in a new account", func() {
+		newAccount := newTestAccount()
+		input := map[string]string{"givenName": "givenName", "surname": "surname", "email": newAccount.Email, "password": password}
+
+		w := serve(handler, newJSONRequest("POST", "/register", input))
+
+		result := map[string]stormpath.Account{}
+		json.Unmarshal(w.Body.Bytes(), &result)
+
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(result["account"].Email).To(Equal(newAccount.Email))
+		Expect(sessionAccount(w).Href).To(Equal(result["account"].Href))
+	})
+})
+
+var _ = Describe("LogoutHandler", func() {
+	handler := LogoutHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should not log out on GET", func() {
+		r := newRequest("GET", "/logout", nil)
+		r.Header.Set("Accept", stormpath.ApplicationJson)
+
+		w := serve(handler, r)
+
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("POST"))
+	})
+
+	It("should reject a form post without a valid CSRF token", func() {
+		w := serve(handler, newFormRequest("POST", "/logout", url.Values{}))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should clear the session account and redirect", func() {
+		token, w := getCSRFToken(handler, "/logout")
+		login := serve(LoginHandler{SessionStore: sessionStore, SessionName: sessionName}, withCookies(
+			newJSONRequest("POST", "/login", map[string]string{"login": account.Email, "password": password}), w,
+		))
+		Expect(sessionAccount(login)).NotTo(BeNil())
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/logout", url.Values{"csrf_token": {token}}), login))
+
+		Expect(response.Code).To(Equal(http.StatusFound))
+		Expect(response.Header().Get("Location")).To(Equal("/"))
+		Expect(sessionAccount(response)).To(BeNil())
+	})
+
+	It("should respond with a 204 No Content to JSON clients", func() {
+		w := serve(handler, newJSONRequest("POST", "/logout", map[string]string{}))
+
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+	})
+})
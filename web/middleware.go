@@ -11,8 +11,72 @@ import (
 	"google.golang.org/appengine/log"
 )
 
+//ErrorHandlerFunc writes the response of a request that failed with the given error
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+
+//DefaultErrorHandler responds with a 500 Internal Server Error without leaking the error details
+func DefaultErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+//ApplicationHandler returns a middleware that loads the application with the given href and stores it in the
+//request context, see ApplicationFromContext. If the application can't be loaded the errorHandler is called
+//instead of the next handler, a nil errorHandler defaults to DefaultErrorHandler.
+func ApplicationHandler(applicationHref string, errorHandler ErrorHandlerFunc) func(http.Handler) http.Handler {
+	if errorHandler == nil {
+		errorHandler = DefaultErrorHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if ApplicationFromContext(r.Context()) != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			app, err := stormpath.GetApplication(ContextFunc(r), applicationHref, stormpath.MakeApplicationCriteria())
+			if err != nil {
+				errorHandler(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithApplication(r.Context(), app)))
+		})
+	}
+}
+
+//AccountHandler returns a middleware that unmarshals the current account stored in the session and stores it
+//in the request context, see AccountFromContext. Requests without a valid session go through without an account.
+func AccountHandler(sessionStore sessions.Store, sessionName string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			session, err := sessionStore.Get(r, sessionName)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			data, ok := session.Values[AccountKey].([]byte)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			account := &stormpath.Account{}
+			if json.Unmarshal(data, account) != nil || account.Href == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithAccount(r.Context(), account)))
+		})
+	}
+}
+
 //ApplicationMiddleware is an http.Handler that stores a given account in the request context
 //to be use by any other handlers in the chain.
+//
+//Deprecated: use ApplicationHandler, this middleware requires App Engine and gorilla/context
 type ApplicationMiddleware struct {
 	ApplicationHref string
 }
@@ -29,7 +93,7 @@ func (m ApplicationMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request)
 			log.Debugf(ctx, "ApplicationMiddleware.ServeHTTP(): Successfully set Application into Context with Key '%v'", ApplicationKey)
 		} else {
 			log.Debugf(ctx, "ApplicationMiddleware.ServeHTTP(): Unable to fetch Application from StormPath: %v", err)
-			panic(err)
+			DefaultErrorHandler(w, r, err)
 		}
 	} else {
 		log.Debugf(ctx, "ApplicationMiddleware.ServeHTTP(): Application was: %#v", app)
@@ -38,6 +102,8 @@ func (m ApplicationMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request)
 
 //AccountMiddleware is an http.Handler that unmarshals the current account store in the session
 //and stores it in the request context to be use by any other handler in the chain
+//
+//Deprecated: use AccountHandler, this middleware requires App Engine and gorilla/context
 type AccountMiddleware struct {
 	SessionStore sessions.Store
 	SessionName  string
@@ -61,7 +127,7 @@ func (m AccountMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 //AuthenticationMiddleware handles authentication for a web application, it should only be apply to http.Handlers
 //that require authentication it checks the session for current account if exists it calls handler else it applies
-//the UnauthorizedHandler
+//the UnauthorizedHandler. See TokenAuthenticationHandler to authenticate the requests by OAuth access token.
 type AuthenticationMiddleware struct {
 	Next                http.Handler
 	SessionStore        sessions.Store
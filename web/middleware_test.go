@@ -0,0 +1,99 @@
+package stormpathweb_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+//sessionWith returns a request carrying a session cookie whose account value is the given data
+func sessionWith(data interface{}) *http.Request {
+	r := newRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+
+	session, _ := sessionStore.Get(r, sessionName)
+	session.Values[AccountKey] = data
+	session.Save(r, w)
+
+	return withCookies(newRequest("GET", "/", nil), w)
+}
+
+var _ = Describe("Middleware", func() {
+	var current *http.Request
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current = r
+	})
+
+	BeforeEach(func() {
+		current = nil
+	})
+
+	Describe("ApplicationHandler", func() {
+		It("should load the application in the request context", func() {
+			r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			serve(ApplicationHandler(app.Href, nil)(next), r)
+
+			Expect(current).NotTo(BeNil())
+			Expect(GetApplication(current).Href).To(Equal(app.Href))
+		})
+
+		It("should keep an application already in the request context", func() {
+			serve(ApplicationHandler("not an href", nil)(next), newRequest("GET", "/", nil))
+
+			Expect(current).NotTo(BeNil())
+			Expect(ApplicationFromContext(current.Context())).To(Equal(app))
+		})
+
+		It("should call the error handler if the application can't be loaded", func() {
+			var handledErr error
+			errorHandler := func(w http.ResponseWriter, r *http.Request, err error) {
+				handledErr = err
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+			r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+
+			w := serve(ApplicationHandler(app.Href+"notFound", errorHandler)(next), r)
+
+			Expect(current).To(BeNil())
+			Expect(handledErr).To(HaveOccurred())
+			Expect(w.Code).To(Equal(http.StatusServiceUnavailable))
+		})
+	})
+
+	Describe("AccountHandler", func() {
+		It("should store the session account in the request context", func() {
+			data, _ := json.Marshal(account)
+
+			serve(AccountHandler(sessionStore, sessionName)(next), sessionWith(data))
+
+			Expect(current).NotTo(BeNil())
+			Expect(IsAuthenticated(current)).To(BeTrue())
+			Expect(GetCurrentAccount(current).Href).To(Equal(account.Href))
+		})
+
+		It("should go through without an account if there is no session", func() {
+			serve(AccountHandler(sessionStore, sessionName)(next), newRequest("GET", "/", nil))
+
+			Expect(current).NotTo(BeNil())
+			Expect(IsAuthenticated(current)).To(BeFalse())
+		})
+
+		It("should ignore an invalid session account", func() {
+			data, _ := json.Marshal(stormpath.Account{})
+
+			for _, r := range []*http.Request{sessionWith([]byte("{")), sessionWith(data), sessionWith("account")} {
+				serve(AccountHandler(sessionStore, sessionName)(next), r)
+
+				Expect(current).NotTo(BeNil())
+				Expect(GetCurrentAccount(current)).To(BeNil())
+			}
+		})
+	})
+})
@@ -0,0 +1,384 @@
+package stormpathweb
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//Default names of the OAuth token cookies
+const (
+	DefaultAccessTokenCookieName  = "access_token"
+	DefaultRefreshTokenCookieName = "refresh_token"
+)
+
+//TokenCookieConfig holds the names and attributes of the cookies storing the OAuth tokens, the zero value uses
+//the DefaultAccessTokenCookieName and DefaultRefreshTokenCookieName cookies on the "/" path. The cookies are
+//always HttpOnly and SameSite=Lax, so they aren't sent with the POST requests of other sites. Lax doesn't keep
+//another site from posting a form to the token handler and getting its own tokens set in these cookies
+//(login CSRF), OAuthTokenHandler guards against it by only accepting script requests in Cookies mode.
+type TokenCookieConfig struct {
+	AccessTokenName  string
+	RefreshTokenName string
+	Domain           string
+	Path             string
+	//Insecure drops the Secure flag, it should only be set for local development over plain HTTP
+	Insecure bool
+}
+
+func (c TokenCookieConfig) accessTokenName() string {
+	if c.AccessTokenName == "" {
+		return DefaultAccessTokenCookieName
+	}
+	return c.AccessTokenName
+}
+
+func (c TokenCookieConfig) refreshTokenName() string {
+	if c.RefreshTokenName == "" {
+		return DefaultRefreshTokenCookieName
+	}
+	return c.RefreshTokenName
+}
+
+func (c TokenCookieConfig) newCookie(name string, value string, maxAge int) *http.Cookie {
+	path := c.Path
+	if path == "" {
+		path = "/"
+	}
+
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   c.Domain,
+		Path:     path,
+		MaxAge:   maxAge,
+		Secure:   !c.Insecure,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+//setTokens sets the token cookies of an OAuth response, they expire with their token
+func (c TokenCookieConfig) setTokens(w http.ResponseWriter, response *stormpath.OAuthResponse) {
+	http.SetCookie(w, c.newCookie(c.accessTokenName(), response.AccessToken, response.ExpiresIn))
+	if response.RefreshToken != "" {
+		http.SetCookie(w, c.newCookie(c.refreshTokenName(), response.RefreshToken, tokenMaxAge(response.RefreshToken)))
+	}
+}
+
+//clearTokens deletes both token cookies
+func (c TokenCookieConfig) clearTokens(w http.ResponseWriter) {
+	http.SetCookie(w, c.newCookie(c.accessTokenName(), "", -1))
+	http.SetCookie(w, c.newCookie(c.refreshTokenName(), "", -1))
+}
+
+func (c TokenCookieConfig) accessToken(r *http.Request) string {
+	return cookieValue(r, c.accessTokenName())
+}
+
+func (c TokenCookieConfig) refreshToken(r *http.Request) string {
+	return cookieValue(r, c.refreshTokenName())
+}
+
+func cookieValue(r *http.Request, name string) string {
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return cookie.Value
+}
+
+//tokenMaxAge returns the seconds left until the exp claim of a JWT, the signature isn't verified since
+//it is only used for the cookie lifetime. It returns 0, a session cookie, if the token has no expiration.
+func tokenMaxAge(token string) int {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0
+	}
+
+	data, err := jwt.DecodeSegment(parts[1])
+	if err != nil {
+		return 0
+	}
+
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if json.Unmarshal(data, &claims) != nil || claims.Exp == 0 {
+		return 0
+	}
+
+	maxAge := claims.Exp - time.Now().Unix()
+	if maxAge <= 0 {
+		return -1
+	}
+	return int(maxAge)
+}
+
+//bearerToken returns the token of an "Authorization: Bearer" header or an empty string
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+//isScriptRequest returns true for the requests a cross site form can't make, JSON or XMLHttpRequest requests
+func isScriptRequest(r *http.Request) bool {
+	return isJSONRequest(r) || r.Header.Get("X-Requested-With") == "XMLHttpRequest"
+}
+
+//oauthError is the body of an OAuth2 error response
+//
+//See: https://tools.ietf.org/html/rfc6749#section-5.2
+type oauthError struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string, description string) {
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	writeJSON(w, status, oauthError{Error: code, ErrorDescription: description})
+}
+
+//OAuthTokenHandler is an http.Handler for an /oauth/token endpoint, it accepts the form posts of the password,
+//refresh_token and client_credentials grants and forwards them to the application OAuth endpoint. The client
+//credentials are the account API key ID and secret, given either as Basic authentication or as the client_id
+//and client_secret values.
+//
+//The values are posted as a form or as a JSON object. The tokens are returned as an OAuth2 JSON response, or with
+//Cookies they are set in HttpOnly cookies and the response is a 204 No Content so browser applications never
+//handle them. The refresh_token grant then uses the refresh token cookie when the refresh_token value is missing.
+//The client_credentials grant is meant for API clients and always gets a JSON response.
+//
+//In Cookies mode the password and refresh_token grants must be JSON requests or have the
+//"X-Requested-With: XMLHttpRequest" header, which another site can't send without a CORS preflight, otherwise
+//any site could log the browser in to an account of its choosing with a plain form post.
+//
+//It requires the application in the request context, see ApplicationHandler.
+type OAuthTokenHandler struct {
+	Cookies      bool
+	CookieConfig TokenCookieConfig
+	//AuthenticationOptions restricts the password grant to an account store or organization
+	AuthenticationOptions stormpath.AuthenticationOptions
+}
+
+//ServeHTTP implements the http.Handler interface for the OAuthTokenHandler type
+func (h OAuthTokenHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	values, err := parseInput(r)
+	if err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "Invalid request")
+		return
+	}
+
+	grantType := values["grant_type"]
+	if h.Cookies && (grantType == "password" || grantType == "refresh_token") && !isScriptRequest(r) {
+		writeOAuthError(w, http.StatusForbidden, "invalid_request", "Cookie token requests must be JSON or XMLHttpRequest requests")
+		return
+	}
+
+	switch grantType {
+	case "password":
+		h.passwordGrant(w, r, app, values)
+	case "refresh_token":
+		h.refreshTokenGrant(w, r, app, values)
+	case "client_credentials":
+		h.clientCredentialsGrant(w, r, app, values)
+	case "":
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "grant_type is required")
+	default:
+		writeOAuthError(w, http.StatusBadRequest, "unsupported_grant_type", "Unsupported grant type "+grantType)
+	}
+}
+
+func (h OAuthTokenHandler) passwordGrant(w http.ResponseWriter, r *http.Request, app *stormpath.Application, values map[string]string) {
+	username := values["username"]
+	password := values["password"]
+	if username == "" || password == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "username and password are required")
+		return
+	}
+
+	response, err := app.GetOAuthToken(ContextFunc(r), username, password, h.AuthenticationOptions)
+	if err != nil {
+		h.grantError(w, r, err)
+		return
+	}
+
+	h.respond(w, response)
+}
+
+func (h OAuthTokenHandler) refreshTokenGrant(w http.ResponseWriter, r *http.Request, app *stormpath.Application, values map[string]string) {
+	refreshToken := values["refresh_token"]
+	if refreshToken == "" && h.Cookies {
+		refreshToken = h.CookieConfig.refreshToken(r)
+	}
+	if refreshToken == "" {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request", "refresh_token is required")
+		return
+	}
+
+	response, err := app.RefreshOAuthToken(ContextFunc(r), refreshToken)
+	if err != nil {
+		if h.Cookies {
+			h.CookieConfig.clearTokens(w)
+		}
+		h.grantError(w, r, err)
+		return
+	}
+
+	h.respond(w, response)
+}
+
+func (h OAuthTokenHandler) clientCredentialsGrant(w http.ResponseWriter, r *http.Request, app *stormpath.Application, values map[string]string) {
+	id, secret, ok := r.BasicAuth()
+	if !ok {
+		id = values["client_id"]
+		secret = values["client_secret"]
+	}
+	if id == "" || secret == "" {
+		writeOAuthError(w, http.StatusUnauthorized, "invalid_client", "Client authentication is required")
+		return
+	}
+
+	response, err := app.GetClientCredentialsToken(ContextFunc(r), id, secret, strings.Fields(values["scope"]))
+	if err != nil {
+		switch err {
+		case stormpath.ErrInvalidAPIKeyCredentials, stormpath.ErrAPIKeyDisabled, stormpath.ErrAccountDisabled:
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+			return
+		}
+		if status, _ := errorStatus(err); status == http.StatusBadRequest {
+			writeOAuthError(w, http.StatusUnauthorized, "invalid_client", stormpath.ErrInvalidAPIKeyCredentials.Error())
+			return
+		}
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+//grantError responds with an invalid_grant error for the Stormpath errors, any other error is an internal error
+func (h OAuthTokenHandler) grantError(w http.ResponseWriter, r *http.Request, err error) {
+	status, message := errorStatus(err)
+	if status == http.StatusInternalServerError {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+	writeOAuthError(w, status, "invalid_grant", message)
+}
+
+func (h OAuthTokenHandler) respond(w http.ResponseWriter, response *stormpath.OAuthResponse) {
+	if h.Cookies {
+		h.CookieConfig.setTokens(w, response)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+//TokenLogoutHandler is an http.Handler that revokes the OAuth tokens of the request and clears the token cookies
+//on POST. The tokens are read from the cookies, the "Authorization: Bearer" header and the access_token and
+//refresh_token values, so both the cookie and the JSON modes of OAuthTokenHandler can log out. Tokens that
+//are invalid or already revoked are ignored.
+//
+//The requests must be JSON or XMLHttpRequest requests, like the OAuthTokenHandler ones, or form posts with the
+//csrf_token of the session of SessionStore and SessionName, the one of the forms of the other handlers sharing the
+//session. Otherwise any other site could log the user out with a plain form post.
+//
+//It requires the application in the request context, see ApplicationHandler.
+type TokenLogoutHandler struct {
+	CookieConfig TokenCookieConfig
+	SessionStore sessions.Store
+	SessionName  string
+	//RedirectURI defaults to "/", JSON requests get a 204 No Content response instead
+	RedirectURI string
+}
+
+//ServeHTTP implements the http.Handler interface for the TokenLogoutHandler type
+func (h TokenLogoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	values, err := parseInput(r)
+	if err != nil || !isScriptRequest(r) && (h.SessionStore == nil || !validCSRFToken(h.SessionStore, h.SessionName, r, values)) {
+		if wantsJSON(r) {
+			writeJSONError(w, http.StatusForbidden, errInvalidCSRFToken.Error())
+			return
+		}
+		http.Error(w, errInvalidCSRFToken.Error(), http.StatusForbidden)
+		return
+	}
+
+	accessToken := h.CookieConfig.accessToken(r)
+	if accessToken == "" {
+		accessToken = bearerToken(r)
+	}
+	if accessToken == "" {
+		accessToken = values["access_token"]
+	}
+
+	refreshToken := h.CookieConfig.refreshToken(r)
+	if refreshToken == "" {
+		refreshToken = values["refresh_token"]
+	}
+
+	h.CookieConfig.clearTokens(w)
+
+	for _, token := range []string{accessToken, refreshToken} {
+		if token == "" {
+			continue
+		}
+		err := app.RevokeToken(ContextFunc(r), token)
+		if err != nil && err != stormpath.ErrInvalidAccessToken {
+			if status, _ := errorStatus(err); status == http.StatusInternalServerError {
+				DefaultErrorHandler(w, r, err)
+				return
+			}
+		}
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	redirect := h.RedirectURI
+	if redirect == "" {
+		redirect = "/"
+	}
+	http.Redirect(w, r, redirectURI(r, redirect), http.StatusFound)
+}
@@ -0,0 +1,223 @@
+package stormpathweb_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+func passwordGrant(username string, password string) url.Values {
+	return url.Values{"grant_type": {"password"}, "username": {username}, "password": {password}}
+}
+
+//oauthResponse decodes the JSON body of a token handler response
+func oauthResponse(w *httptest.ResponseRecorder) map[string]interface{} {
+	response := map[string]interface{}{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	return response
+}
+
+//xhr marks a request as sent by a script, like most JavaScript HTTP clients do
+func xhr(r *http.Request) *http.Request {
+	r.Header.Set("X-Requested-With", "XMLHttpRequest")
+	return r
+}
+
+var _ = Describe("OAuthTokenHandler", func() {
+	handler := OAuthTokenHandler{}
+	cookieHandler := OAuthTokenHandler{Cookies: true}
+
+	It("should only accept POST requests", func() {
+		w := serve(handler, newRequest("GET", "/oauth/token", nil))
+
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(w.Header().Get("Allow")).To(Equal("POST"))
+	})
+
+	It("should reject a missing or unsupported grant type", func() {
+		w := serve(handler, newFormRequest("POST", "/oauth/token", url.Values{}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Cache-Control")).To(Equal("no-store"))
+		Expect(oauthResponse(w)["error"]).To(Equal("invalid_request"))
+
+		w = serve(handler, newFormRequest("POST", "/oauth/token", url.Values{"grant_type": {"implicit"}}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(oauthResponse(w)["error"]).To(Equal("unsupported_grant_type"))
+	})
+
+	It("should require client authentication for the client_credentials grant", func() {
+		w := serve(handler, newFormRequest("POST", "/oauth/token", url.Values{"grant_type": {"client_credentials"}}))
+
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+		Expect(w.Header().Get("WWW-Authenticate")).To(HavePrefix("Basic"))
+		Expect(oauthResponse(w)["error"]).To(Equal("invalid_client"))
+	})
+
+	It("should return the tokens as JSON", func() {
+		w := serve(handler, newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, password)))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+		Expect(oauthResponse(w)["access_token"]).NotTo(BeEmpty())
+		Expect(oauthResponse(w)["refresh_token"]).NotTo(BeEmpty())
+		Expect(w.Result().Cookies()).To(BeEmpty())
+	})
+
+	It("should accept the values as a JSON object", func() {
+		input := map[string]string{"grant_type": "password", "username": account.Email, "password": password}
+
+		w := serve(handler, newJSONRequest("POST", "/oauth/token", input))
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(oauthResponse(w)["access_token"]).NotTo(BeEmpty())
+	})
+
+	It("should reject invalid credentials", func() {
+		w := serve(handler, newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, "wrong")))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(oauthResponse(w)["error"]).To(Equal("invalid_grant"))
+	})
+
+	Describe("with cookies", func() {
+		It("should reject the form posts of other sites", func() {
+			for _, values := range []url.Values{passwordGrant(account.Email, password), {"grant_type": {"refresh_token"}}} {
+				w := serve(cookieHandler, newFormRequest("POST", "/oauth/token", values))
+
+				Expect(w.Code).To(Equal(http.StatusForbidden))
+				Expect(oauthResponse(w)["error"]).To(Equal("invalid_request"))
+				Expect(w.Result().Cookies()).To(BeEmpty())
+			}
+		})
+
+		It("should set the tokens in secure cookies", func() {
+			w := serve(cookieHandler, xhr(newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, password))))
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(w.Body.Len()).To(BeZero())
+
+			for _, name := range []string{DefaultAccessTokenCookieName, DefaultRefreshTokenCookieName} {
+				cookie := responseCookie(w, name)
+				Expect(cookie).NotTo(BeNil())
+				Expect(cookie.Value).NotTo(BeEmpty())
+				Expect(cookie.HttpOnly).To(BeTrue())
+				Expect(cookie.Secure).To(BeTrue())
+				Expect(cookie.SameSite).To(Equal(http.SameSiteLaxMode))
+				Expect(cookie.Path).To(Equal("/"))
+				Expect(cookie.MaxAge).To(BeNumerically(">", 0))
+			}
+		})
+
+		It("should use the cookie configuration", func() {
+			handler := OAuthTokenHandler{
+				Cookies:      true,
+				CookieConfig: TokenCookieConfig{AccessTokenName: "at", RefreshTokenName: "rt", Domain: "example.com", Path: "/api", Insecure: true},
+			}
+			input := map[string]string{"grant_type": "password", "username": account.Email, "password": password}
+
+			w := serve(handler, newJSONRequest("POST", "/oauth/token", input))
+
+			cookie := responseCookie(w, "at")
+			Expect(cookie).NotTo(BeNil())
+			Expect(cookie.Domain).To(Equal("example.com"))
+			Expect(cookie.Path).To(Equal("/api"))
+			Expect(cookie.Secure).To(BeFalse())
+			Expect(responseCookie(w, "rt")).NotTo(BeNil())
+		})
+
+		It("should refresh the tokens with the refresh token cookie", func() {
+			login := serve(cookieHandler, xhr(newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, password))))
+
+			r := xhr(newFormRequest("POST", "/oauth/token", url.Values{"grant_type": {"refresh_token"}}))
+			w := serve(cookieHandler, withCookies(r, login))
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+			Expect(responseCookie(w, DefaultAccessTokenCookieName).Value).NotTo(Equal(responseCookie(login, DefaultAccessTokenCookieName).Value))
+		})
+
+		It("should clear the cookies of an invalid refresh token", func() {
+			r := xhr(newFormRequest("POST", "/oauth/token", url.Values{"grant_type": {"refresh_token"}, "refresh_token": {"invalid"}}))
+
+			w := serve(cookieHandler, r)
+
+			Expect(w.Code).To(Equal(http.StatusBadRequest))
+			Expect(responseCookie(w, DefaultRefreshTokenCookieName).MaxAge).To(BeNumerically("<", 0))
+		})
+	})
+})
+
+var _ = Describe("TokenLogoutHandler", func() {
+	handler := TokenLogoutHandler{}
+
+	It("should only accept POST requests", func() {
+		w := serve(handler, newRequest("GET", "/logout", nil))
+
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("should clear the token cookies", func() {
+		w := serve(handler, xhr(newFormRequest("POST", "/logout?next=/bye", url.Values{})))
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/bye"))
+		for _, name := range []string{DefaultAccessTokenCookieName, DefaultRefreshTokenCookieName} {
+			cookie := responseCookie(w, name)
+			Expect(cookie.MaxAge).To(BeNumerically("<", 0))
+			Expect(cookie.HttpOnly).To(BeTrue())
+			Expect(cookie.Secure).To(BeTrue())
+			Expect(cookie.SameSite).To(Equal(http.SameSiteLaxMode))
+		}
+
+		w = serve(handler, newJSONRequest("POST", "/logout", map[string]string{}))
+
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("should reject the form posts of other sites", func() {
+		r := newFormRequest("POST", "/logout", url.Values{})
+		r.AddCookie(&http.Cookie{Name: DefaultAccessTokenCookieName, Value: "token"})
+
+		w := serve(handler, r)
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Result().Cookies()).To(BeEmpty())
+
+		r = newFormRequest("POST", "/logout", url.Values{"csrf_token": {"invalid"}})
+		r.AddCookie(&http.Cookie{Name: DefaultAccessTokenCookieName, Value: "token"})
+		w = serve(TokenLogoutHandler{SessionStore: sessionStore, SessionName: sessionName}, r)
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Result().Cookies()).To(BeEmpty())
+	})
+
+	It("should accept a form post with the CSRF token of the session", func() {
+		token, session := getCSRFToken(LogoutHandler{SessionStore: sessionStore, SessionName: sessionName}, "/logout")
+		r := withCookies(newFormRequest("POST", "/logout", url.Values{"csrf_token": {token}}), session)
+
+		w := serve(TokenLogoutHandler{SessionStore: sessionStore, SessionName: sessionName}, r)
+
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(w.Header().Get("Location")).To(Equal("/"))
+		Expect(responseCookie(w, DefaultAccessTokenCookieName).MaxAge).To(BeNumerically("<", 0))
+	})
+
+	It("should revoke the tokens of the cookies", func() {
+		login := serve(OAuthTokenHandler{Cookies: true}, xhr(newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, password))))
+
+		w := serve(handler, withCookies(newJSONRequest("POST", "/logout", map[string]string{}), login))
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+
+		_, err := app.ValidateToken(ctx, responseCookie(login, DefaultAccessTokenCookieName).Value)
+		Expect(err).To(HaveOccurred())
+		_, err = app.RefreshOAuthToken(ctx, responseCookie(login, DefaultRefreshTokenCookieName).Value)
+		Expect(err).To(HaveOccurred())
+	})
+})
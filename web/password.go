@@ -0,0 +1,245 @@
+package stormpathweb
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//sptokenParam is the query parameter holding the Stormpath password reset and email verification tokens
+const sptokenParam = "sptoken"
+
+//DefaultForgotPasswordFields are the inputs of the forgot password form
+var DefaultForgotPasswordFields = []FormField{
+	{Name: "email", Label: "Email", Type: "email", Required: true},
+}
+
+//DefaultChangePasswordFields are the inputs of the change password form
+var DefaultChangePasswordFields = []FormField{
+	{Name: "password", Label: "New Password", Type: "password", Required: true},
+	{Name: confirmPasswordField, Label: "Confirm New Password", Type: "password", Required: true},
+}
+
+//ForgotPasswordHandler is an http.Handler that renders the forgot password form on GET and sends the password reset
+//email on POST. The response is the same whether the email belongs to an account or not so it can't be used
+//to find out the registered emails.
+//
+//It requires the application in the request context, see ApplicationHandler.
+type ForgotPasswordHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultForgotPasswordTemplate
+	Template *template.Template
+	//AccountStoreHref restricts the account lookup to the given account store
+	AccountStoreHref string
+	//SentMessage is displayed once the form has been submitted
+	SentMessage string
+}
+
+//ServeHTTP implements the http.Handler interface for the ForgotPasswordHandler type
+func (h ForgotPasswordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.view().render(w, r, http.StatusOK, FormData{Fields: DefaultForgotPasswordFields})
+	case "POST":
+		h.sendResetEmail(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h ForgotPasswordHandler) sendResetEmail(w http.ResponseWriter, r *http.Request) {
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	view := h.view()
+	data := FormData{Fields: DefaultForgotPasswordFields}
+
+	values, err := parseInput(r)
+	if err != nil {
+		view.fail(w, r, http.StatusBadRequest, "Invalid request", nil, data)
+		return
+	}
+
+	if !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		view.fail(w, r, http.StatusForbidden, errInvalidCSRFToken.Error(), nil, data)
+		return
+	}
+
+	if missing := missingFields(DefaultForgotPasswordFields, values); missing != nil {
+		view.fail(w, r, http.StatusBadRequest, "", missing, data)
+		return
+	}
+
+	_, err = app.SendPasswordResetEmail(ContextFunc(r), values["email"], stormpath.PasswordResetOptions{AccountStoreHref: h.AccountStoreHref})
+	if err != nil {
+		if status, _ := errorStatus(err); status == http.StatusInternalServerError {
+			DefaultErrorHandler(w, r, err)
+			return
+		}
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	message := h.SentMessage
+	if message == "" {
+		message = "If an account exists for this email, a password reset link has been sent to it."
+	}
+	view.render(w, r, http.StatusOK, FormData{Fields: []FormField{}, Message: message})
+}
+
+func (h ForgotPasswordHandler) view() formView {
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultForgotPasswordTemplate
+	}
+	return formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}
+}
+
+//ChangePasswordHandler is an http.Handler for the link of the password reset email (?sptoken=...), the token is
+//validated before rendering the change password form on GET and the new password is set on POST. When PasswordStrength
+//is given the new password is validated locally first and every violated rule is displayed.
+//
+//On success AutoLogin stores the account in the session, then SuccessHook is called and the user is redirected
+//to RedirectURI. JSON requests get the account as a JSON response instead.
+//It requires the application in the request context, see ApplicationHandler.
+type ChangePasswordHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultChangePasswordTemplate
+	Template         *template.Template
+	PasswordStrength *stormpath.PasswordStrength
+	AutoLogin        bool
+	SuccessHook      SuccessHook
+	//RedirectURI defaults to "/login"
+	RedirectURI string
+}
+
+//ServeHTTP implements the http.Handler interface for the ChangePasswordHandler type
+func (h ChangePasswordHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" && r.Method != "POST" {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	view := h.view()
+	data := FormData{Fields: DefaultChangePasswordFields}
+	token := r.URL.Query().Get(sptokenParam)
+
+	if token == "" {
+		view.fail(w, r, http.StatusBadRequest, "The password reset link is invalid or has expired.", nil, FormData{Fields: []FormField{}})
+		return
+	}
+
+	if r.Method == "GET" {
+		_, err := app.ValidatePasswordResetToken(ContextFunc(r), token)
+		if err != nil {
+			status, _ := errorStatus(err)
+			view.fail(w, r, status, "The password reset link is invalid or has expired.", nil, FormData{Fields: []FormField{}})
+			return
+		}
+
+		view.render(w, r, http.StatusOK, data)
+		return
+	}
+
+	values, err := parseInput(r)
+	if err != nil {
+		view.fail(w, r, http.StatusBadRequest, "Invalid request", nil, data)
+		return
+	}
+
+	if !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		view.fail(w, r, http.StatusForbidden, errInvalidCSRFToken.Error(), nil, data)
+		return
+	}
+
+	if missing := missingFields([]FormField{DefaultChangePasswordFields[0]}, values); missing != nil {
+		view.fail(w, r, http.StatusBadRequest, "", missing, data)
+		return
+	}
+
+	if _, ok := values[confirmPasswordField]; (ok || !isJSONRequest(r)) && values[confirmPasswordField] != values["password"] {
+		view.fail(w, r, http.StatusBadRequest, "Passwords do not match", nil, data)
+		return
+	}
+
+	if h.PasswordStrength != nil {
+		if err := h.PasswordStrength.Validate(values["password"]); err != nil {
+			view.fail(w, r, http.StatusBadRequest, "", err.(stormpath.PasswordStrengthError).Violations, data)
+			return
+		}
+	}
+
+	account, err := app.ResetPassword(ContextFunc(r), token, values["password"], stormpath.PasswordResetOptions{ExpandAccount: true})
+	if err != nil {
+		status, message := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			DefaultErrorHandler(w, r, err)
+			return
+		}
+		view.fail(w, r, status, message, nil, data)
+		return
+	}
+
+	if !succeed(w, r, h.SessionStore, h.SessionName, h.AutoLogin, h.SuccessHook, account) {
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"account": account})
+		return
+	}
+
+	redirect := h.RedirectURI
+	if redirect == "" {
+		redirect = "/login"
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (h ChangePasswordHandler) view() formView {
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultChangePasswordTemplate
+	}
+	return formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}
+}
+
+//succeed runs the auto login and the success hook of a password or verification handler,
+//it returns false if the request has been aborted
+func succeed(w http.ResponseWriter, r *http.Request, store sessions.Store, sessionName string, autoLogin bool, hook SuccessHook, account *stormpath.Account) bool {
+	if autoLogin && account.Status == stormpath.Enabled {
+		err := saveAccountInSession(store, sessionName, account, w, r)
+		if err != nil {
+			DefaultErrorHandler(w, r, err)
+			return false
+		}
+	}
+
+	if hook != nil {
+		err := hook(w, r, account)
+		if err != nil {
+			DefaultErrorHandler(w, r, err)
+			return false
+		}
+	}
+
+	return true
+}
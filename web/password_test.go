@@ -0,0 +1,107 @@
+package stormpathweb_test
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+var _ = Describe("ForgotPasswordHandler", func() {
+	handler := ForgotPasswordHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should render the forgot password form", func() {
+		token, w := getCSRFToken(handler, "/forgot")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring(`name="email"`))
+		Expect(token).NotTo(BeEmpty())
+	})
+
+	It("should reject a form post without a valid CSRF token", func() {
+		w := serve(handler, newFormRequest("POST", "/forgot", url.Values{"email": {account.Email}}))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("invalid or missing CSRF token"))
+	})
+
+	It("should respond the same way whether the account exists or not", func() {
+		for _, email := range []string{account.Email, "unknown@test.org"} {
+			w := serve(handler, newJSONRequest("POST", "/forgot", map[string]string{"email": email}))
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		}
+	})
+
+	It("should render the sent message to browsers", func() {
+		token, w := getCSRFToken(handler, "/forgot")
+		values := url.Values{"csrf_token": {token}, "email": {account.Email}}
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/forgot", values), w))
+
+		Expect(response.Code).To(Equal(http.StatusOK))
+		Expect(response.Body.String()).To(ContainSubstring("a password reset link has been sent"))
+		Expect(response.Body.String()).NotTo(ContainSubstring(`name="email"`))
+	})
+})
+
+var _ = Describe("ChangePasswordHandler", func() {
+	handler := ChangePasswordHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should reject a request without a token", func() {
+		w := serve(handler, newRequest("GET", "/change", nil))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(HavePrefix("text/html"))
+		Expect(w.Body.String()).To(ContainSubstring("invalid or has expired"))
+
+		w = serve(handler, newJSONRequest("POST", "/change", map[string]string{"password": password}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+	})
+
+	It("should reject an invalid token", func() {
+		w := serve(handler, newRequest("GET", "/change?sptoken=invalid", nil))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("invalid or has expired"))
+	})
+
+	It("should reject a form post without a valid CSRF token", func() {
+		values := url.Values{"password": {password}, "confirmPassword": {password}}
+
+		w := serve(handler, newFormRequest("POST", "/change?sptoken=token", values))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require the password confirmation to match", func() {
+		input := map[string]string{"password": password, "confirmPassword": "other"}
+
+		w := serve(handler, newJSONRequest("POST", "/change?sptoken=token", input))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("Passwords do not match"))
+	})
+
+	It("should list the password strength violations", func() {
+		handler := ChangePasswordHandler{
+			SessionStore:     sessionStore,
+			SessionName:      sessionName,
+			PasswordStrength: &stormpath.PasswordStrength{MinLength: 8, MaxLength: 100, MinNumeric: 1},
+		}
+		//The change password form is only rendered for a valid token, the CSRF token of the session is shared
+		token, w := getCSRFToken(ForgotPasswordHandler{SessionStore: sessionStore, SessionName: sessionName}, "/forgot")
+		values := url.Values{"csrf_token": {token}, "password": {"short"}, "confirmPassword": {"short"}}
+
+		response := serve(handler, withCookies(newFormRequest("POST", "/change?sptoken=token", values), w))
+
+		Expect(response.Code).To(Equal(http.StatusBadRequest))
+		Expect(response.Body.String()).To(ContainSubstring("<li>minimum length is 8</li>"))
+		Expect(response.Body.String()).To(ContainSubstring("<li>requires at least 1 numeric characters</li>"))
+	})
+})
@@ -0,0 +1,191 @@
+package stormpathweb
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//confirmPasswordField is the name of the optional password confirmation field of the registration form
+const confirmPasswordField = "confirmPassword"
+
+//DefaultRegisterFields are the inputs of the registration form
+var DefaultRegisterFields = []FormField{
+	{Name: "givenName", Label: "First Name", Type: "text", Required: true},
+	{Name: "surname", Label: "Last Name", Type: "text", Required: true},
+	{Name: "email", Label: "Email", Type: "email", Required: true},
+	{Name: "password", Label: "Password", Type: "password", Required: true},
+}
+
+//RegisterHandler is an http.Handler that renders the registration form on GET and registers a new account in the
+//application on POST. If the account is enabled right away it is logged in and the user is redirected to RedirectURI,
+//if it needs to verify its email first the form is rendered again with VerificationMessage.
+//JSON requests get the created account as a JSON response instead.
+//
+//The username, email, password, givenName, middleName and surname fields map to the account attributes,
+//any other field is stored in the account custom data. A "confirmPassword" field must match the password.
+//Only the given Fields are accepted. It requires the application in the request context, see ApplicationHandler.
+type RegisterHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultRegisterTemplate
+	Template *template.Template
+	//Fields defaults to DefaultRegisterFields
+	Fields []FormField
+	//RedirectURI defaults to "/"
+	RedirectURI         string
+	VerificationMessage string
+}
+
+//ServeHTTP implements the http.Handler interface for the RegisterHandler type
+func (h RegisterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.render(w, r, http.StatusOK, FormData{})
+	case "POST":
+		h.register(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h RegisterHandler) register(w http.ResponseWriter, r *http.Request) {
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	values, err := parseInput(r)
+	if err != nil {
+		h.fail(w, r, http.StatusBadRequest, "Invalid request", nil, nil)
+		return
+	}
+
+	if !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		h.fail(w, r, http.StatusForbidden, errInvalidCSRFToken.Error(), values, nil)
+		return
+	}
+
+	if missing := missingFields(h.fields(), values); missing != nil {
+		h.fail(w, r, http.StatusBadRequest, "", values, missing)
+		return
+	}
+
+	if h.hasField(confirmPasswordField) && values[confirmPasswordField] != values["password"] {
+		h.fail(w, r, http.StatusBadRequest, "Passwords do not match", values, nil)
+		return
+	}
+
+	account := h.newAccount(values)
+
+	err = app.RegisterAccount(ContextFunc(r), account)
+	if err != nil {
+		status, message := errorStatus(err)
+		h.fail(w, r, status, message, values, nil)
+		return
+	}
+
+	if account.Status == stormpath.Enabled {
+		err = saveAccountInSession(h.SessionStore, h.SessionName, account, w, r)
+		if err != nil {
+			DefaultErrorHandler(w, r, err)
+			return
+		}
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"account": account})
+		return
+	}
+
+	if account.Status != stormpath.Enabled {
+		message := h.VerificationMessage
+		if message == "" {
+			message = "Your account has been created, please check your email to verify it."
+		}
+		h.render(w, r, http.StatusOK, FormData{Message: message, Fields: []FormField{}})
+		return
+	}
+
+	redirect := h.RedirectURI
+	if redirect == "" {
+		redirect = "/"
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+//newAccount maps the declared fields values to a new account
+func (h RegisterHandler) newAccount(values map[string]string) *stormpath.Account {
+	account := &stormpath.Account{}
+	customData := stormpath.CustomData{}
+
+	for _, field := range h.fields() {
+		value := values[field.Name]
+
+		switch field.Name {
+		case "username":
+			account.Username = value
+		case "email":
+			account.Email = value
+		case "password":
+			account.Password = value
+		case "givenName":
+			account.GivenName = value
+		case "middleName":
+			account.MiddleName = value
+		case "surname":
+			account.Surname = value
+		case confirmPasswordField:
+		default:
+			if value != "" {
+				customData[field.Name] = value
+			}
+		}
+	}
+
+	if len(customData) > 0 {
+		account.CustomData = &customData
+	}
+
+	return account
+}
+
+func (h RegisterHandler) fail(w http.ResponseWriter, r *http.Request, status int, message string, values map[string]string, errors []string) {
+	h.view().fail(w, r, status, message, errors, FormData{Fields: h.fields(), Values: values})
+}
+
+//render renders the registration form, data.Fields is set to the handler fields unless it is already set
+func (h RegisterHandler) render(w http.ResponseWriter, r *http.Request, status int, data FormData) {
+	if data.Fields == nil {
+		data.Fields = h.fields()
+	}
+	h.view().render(w, r, status, data)
+}
+
+func (h RegisterHandler) view() formView {
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultRegisterTemplate
+	}
+	return formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}
+}
+
+func (h RegisterHandler) fields() []FormField {
+	if h.Fields == nil {
+		return DefaultRegisterFields
+	}
+	return h.Fields
+}
+
+func (h RegisterHandler) hasField(name string) bool {
+	for _, field := range h.fields() {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package stormpathweb
+
+import "html/template"
+
+//formTemplateHTML is the default layout of all the built-in forms, the Fields are rendered as labeled inputs
+const formTemplateHTML = `<!doctype html>
+<html lang="en">
+    <head>
+        <meta charset="utf-8">
+        <title>{{.Title}}</title>
+    </head>
+    <body>
+        <h1>{{.Title}}</h1>
+        {{with .Data.Message}}<p class="message">{{.}}</p>{{end}}
+        {{with .Data.Error}}<p class="error">{{.}}</p>{{end}}
+        {{with .Data.Errors}}<ul class="errors">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+        {{if or .Data.Fields .NoFields}}
+        <form method="post" action="{{.Data.Action}}">
+            <input type="hidden" name="csrf_token" value="{{.Data.CSRFToken}}">
+            {{$values := .Data.Values}}
+            {{range .Data.Fields}}
+            <label>{{.Label}}
+                <input type="{{.Type}}" name="{{.Name}}"{{if ne .Type "password"}} value="{{index $values .Name}}"{{end}}{{if .Required}} required{{end}}>
+            </label>
+            {{end}}
+            <button type="submit">{{.Submit}}</button>
+        </form>
+        {{end}}
+    </body>
+</html>
+`
+
+//formPage wraps the FormData with the page texts of the built-in templates, the form is only rendered
+//if it has fields unless NoFields is set (e.g. the logout form)
+type formPage struct {
+	Title    string
+	Submit   string
+	NoFields bool
+	Data     FormData
+}
+
+//newFormTemplate creates a built-in form template with the given title and submit button text,
+//it is executed with a FormData like any custom template
+func newFormTemplate(name string, title string, submit string, noFields bool) *template.Template {
+	t := template.New(name).Funcs(template.FuncMap{
+		"page": func(data FormData) formPage {
+			return formPage{Title: title, Submit: submit, NoFields: noFields && data.Message == "", Data: data}
+		},
+	})
+	template.Must(t.New("layout").Parse(formTemplateHTML))
+
+	return template.Must(t.Parse(`{{template "layout" page .}}`))
+}
+
+//Default form templates, they can be replaced with any template executed with a FormData
+var (
+	DefaultLoginTemplate    = newFormTemplate("login", "Login", "Login", false)
+	DefaultRegisterTemplate = newFormTemplate("register", "Create Account", "Create Account", false)
+	DefaultLogoutTemplate   = newFormTemplate("logout", "Logout", "Logout", true)
+
+	DefaultForgotPasswordTemplate = newFormTemplate("forgot", "Forgot Password", "Send Reset Link", false)
+	DefaultChangePasswordTemplate = newFormTemplate("change", "Change Password", "Change Password", false)
+	DefaultVerifyEmailTemplate    = newFormTemplate("verify", "Verify Email", "Resend Verification Email", false)
+)
@@ -0,0 +1,179 @@
+package stormpathweb
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/sappenin/stormpath-sdk-go"
+	"golang.org/x/net/context"
+)
+
+//UnauthenticatedResponse selects how TokenAuthenticationHandler responds to requests without a valid access token
+type UnauthenticatedResponse int
+
+const (
+	//RespondByAccept responds like RespondUnauthorized to JSON clients and like RedirectToLogin to browsers
+	RespondByAccept UnauthenticatedResponse = iota
+	//RespondUnauthorized responds with a 401 Unauthorized JSON error
+	RespondUnauthorized
+	//RedirectToLogin redirects to the LoginURI with the requested URI as the next parameter
+	RedirectToLogin
+)
+
+//TokenAuthenticationOptions configures a TokenAuthenticationHandler, the zero value validates the tokens against
+//Stormpath, reads the cookies set by an OAuthTokenHandler with the default TokenCookieConfig and chooses
+//the unauthenticated response from the request Accept header.
+type TokenAuthenticationOptions struct {
+	CookieConfig TokenCookieConfig
+	Strategy     stormpath.TokenValidationStrategy
+	//AccountCriteria is used to fetch the account of the token, it defaults to MakeAccountCriteria()
+	AccountCriteria stormpath.Criteria
+	Unauthenticated UnauthenticatedResponse
+	//LoginURI defaults to "/login"
+	LoginURI string
+	//ErrorHandler defaults to DefaultErrorHandler
+	ErrorHandler ErrorHandlerFunc
+}
+
+//TokenAuthenticationHandler returns a middleware that authenticates the requests by OAuth access token, taken from
+//the "Authorization: Bearer" header or else from the access token cookie, and stores its account in the request
+//context, see AccountFromContext. When the access token cookie is missing or no longer valid the refresh token
+//cookie is used to get new tokens transparently, the cookies are updated on the response. Bearer tokens are never
+//refreshed, API clients do it themselves.
+//
+//The bearer tokens of the client_credentials grant (see Application.GetClientCredentialsToken) aren't Stormpath
+//access tokens, they are accepted through Application.AuthenticateAPIRequest and authenticate the account of
+//their API key. Their scopes aren't checked.
+//
+//Requests without a valid token or whose account isn't enabled don't reach the next handler, see
+//UnauthenticatedResponse. It requires the application in the request context, see ApplicationHandler.
+func TokenAuthenticationHandler(options TokenAuthenticationOptions) func(http.Handler) http.Handler {
+	if options.AccountCriteria == nil {
+		options.AccountCriteria = stormpath.MakeAccountCriteria()
+	}
+	if options.LoginURI == "" {
+		options.LoginURI = "/login"
+	}
+	if options.ErrorHandler == nil {
+		options.ErrorHandler = DefaultErrorHandler
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			app := GetApplication(r)
+			if app == nil {
+				options.ErrorHandler(w, r, errNoApplication)
+				return
+			}
+
+			ctx := ContextFunc(r)
+
+			accountHref, err := options.authenticate(ctx, w, r, app)
+			if err != nil {
+				options.ErrorHandler(w, r, err)
+				return
+			}
+			if accountHref == "" {
+				options.unauthenticated(w, r)
+				return
+			}
+
+			account, err := stormpath.GetAccount(ctx, accountHref, options.AccountCriteria)
+			if err != nil {
+				if status, _ := errorStatus(err); status == http.StatusInternalServerError {
+					options.ErrorHandler(w, r, err)
+					return
+				}
+				options.unauthenticated(w, r)
+				return
+			}
+			if account.Status != stormpath.Enabled {
+				options.unauthenticated(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithAccount(ctx, account)))
+		})
+	}
+}
+
+//authenticate returns the account href of the request token, refreshing the token cookies if needed.
+//It returns an empty href if the request isn't authenticated and an error only if Stormpath couldn't be reached.
+func (options TokenAuthenticationOptions) authenticate(ctx context.Context, w http.ResponseWriter, r *http.Request, app *stormpath.Application) (string, error) {
+	if token := bearerToken(r); token != "" {
+		accountHref, err := options.validate(ctx, app, token, options.Strategy)
+		if accountHref != "" || err != nil {
+			return accountHref, err
+		}
+		return authenticateAPIKeyToken(ctx, r, app)
+	}
+
+	if token := options.CookieConfig.accessToken(r); token != "" {
+		accountHref, err := options.validate(ctx, app, token, options.Strategy)
+		if accountHref != "" || err != nil {
+			return accountHref, err
+		}
+	}
+
+	refreshToken := options.CookieConfig.refreshToken(r)
+	if refreshToken == "" {
+		return "", nil
+	}
+
+	response, err := app.RefreshOAuthToken(ctx, refreshToken)
+	if err != nil {
+		if status, _ := errorStatus(err); status == http.StatusInternalServerError {
+			return "", err
+		}
+		options.CookieConfig.clearTokens(w)
+		return "", nil
+	}
+	options.CookieConfig.setTokens(w, response)
+
+	//The new token comes straight from Stormpath so checking it locally is enough
+	return options.validate(ctx, app, response.AccessToken, stormpath.LocalTokenValidation)
+}
+
+//validate returns the account href of a Stormpath access token or an empty href if the token isn't valid
+func (options TokenAuthenticationOptions) validate(ctx context.Context, app *stormpath.Application, token string, strategy stormpath.TokenValidationStrategy) (string, error) {
+	accessToken, err := app.ValidateTokenWithStrategy(ctx, token, strategy)
+	if err == nil {
+		return accessToken.AccountHref(), nil
+	}
+
+	if err == stormpath.ErrInvalidAccessToken || err == stormpath.ErrAccessTokenExpired {
+		return "", nil
+	}
+	if status, _ := errorStatus(err); status == http.StatusBadRequest {
+		return "", nil
+	}
+	return "", err
+}
+
+//authenticateAPIKeyToken returns the account href of a client_credentials bearer token or an empty href
+//if the token or its API key isn't valid
+func authenticateAPIKeyToken(ctx context.Context, r *http.Request, app *stormpath.Application) (string, error) {
+	result, err := app.AuthenticateAPIRequest(ctx, r)
+	if err == nil {
+		return result.Account.Href, nil
+	}
+
+	switch err {
+	case stormpath.ErrInvalidAuthorizationHeader, stormpath.ErrInvalidAPIKeyCredentials, stormpath.ErrAPIKeyDisabled, stormpath.ErrAccountDisabled:
+		return "", nil
+	}
+	if status, _ := errorStatus(err); status == http.StatusBadRequest {
+		return "", nil
+	}
+	return "", err
+}
+
+func (options TokenAuthenticationOptions) unauthenticated(w http.ResponseWriter, r *http.Request) {
+	if options.Unauthenticated == RespondUnauthorized || (options.Unauthenticated == RespondByAccept && wantsJSON(r)) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSONError(w, http.StatusUnauthorized, http.StatusText(http.StatusUnauthorized))
+		return
+	}
+
+	http.Redirect(w, r, options.LoginURI+"?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+}
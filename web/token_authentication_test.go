@@ -0,0 +1,149 @@
+package stormpathweb_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+//tokenCookies returns a response holding the token cookies of a new login of the test account
+func tokenCookies() *httptest.ResponseRecorder {
+	w := serve(OAuthTokenHandler{Cookies: true}, xhr(newFormRequest("POST", "/oauth/token", passwordGrant(account.Email, password))))
+	Expect(w.Code).To(Equal(http.StatusNoContent))
+	return w
+}
+
+var _ = Describe("TokenAuthenticationHandler", func() {
+	var current *http.Request
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current = r
+	})
+
+	handler := TokenAuthenticationHandler(TokenAuthenticationOptions{})(next)
+
+	BeforeEach(func() {
+		current = nil
+	})
+
+	Describe("unauthenticated requests", func() {
+		It("should redirect browsers to the login page", func() {
+			w := serve(handler, newRequest("GET", "/private?x=1", nil))
+
+			Expect(current).To(BeNil())
+			Expect(w.Code).To(Equal(http.StatusFound))
+			Expect(w.Header().Get("Location")).To(Equal("/login?next=%2Fprivate%3Fx%3D1"))
+		})
+
+		It("should respond with a 401 Unauthorized to JSON clients", func() {
+			r := newRequest("GET", "/private", nil)
+			r.Header.Set("Accept", stormpath.ApplicationJson)
+
+			w := serve(handler, r)
+
+			Expect(current).To(BeNil())
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+			Expect(w.Header().Get("WWW-Authenticate")).To(Equal("Bearer"))
+			Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+		})
+
+		It("should use the configured response", func() {
+			unauthorized := TokenAuthenticationHandler(TokenAuthenticationOptions{Unauthenticated: RespondUnauthorized})(next)
+			w := serve(unauthorized, newRequest("GET", "/private", nil))
+
+			Expect(w.Code).To(Equal(http.StatusUnauthorized))
+
+			redirect := TokenAuthenticationHandler(TokenAuthenticationOptions{Unauthenticated: RedirectToLogin, LoginURI: "/signin"})(next)
+			r := newRequest("GET", "/private", nil)
+			r.Header.Set("Accept", stormpath.ApplicationJson)
+			w = serve(redirect, r)
+
+			Expect(w.Code).To(Equal(http.StatusFound))
+			Expect(w.Header().Get("Location")).To(Equal("/signin?next=%2Fprivate"))
+			Expect(current).To(BeNil())
+		})
+
+		It("should call the error handler without an application", func() {
+			w := serve(handler, httptest.NewRequest("GET", "/private", nil))
+
+			Expect(current).To(BeNil())
+			Expect(w.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	It("should authenticate a bearer access token", func() {
+		response, _ := app.GetOAuthToken(ctx, account.Email, password)
+		r := newRequest("GET", "/private", nil)
+		r.Header.Set("Authorization", "Bearer "+response.AccessToken)
+
+		serve(handler, r)
+
+		Expect(current).NotTo(BeNil())
+		Expect(GetCurrentAccount(current).Href).To(Equal(account.Href))
+		Expect(GetApplication(current)).NotTo(BeNil())
+	})
+
+	It("should authenticate a client_credentials bearer token", func() {
+		apiKey, _ := account.CreateAPIKey(ctx)
+		response, _ := app.GetClientCredentialsToken(ctx, apiKey.ID, apiKey.Secret, nil)
+		r := newRequest("GET", "/private", nil)
+		r.Header.Set("Authorization", "Bearer "+response.AccessToken)
+
+		serve(handler, r)
+
+		Expect(current).NotTo(BeNil())
+		Expect(GetCurrentAccount(current).Href).To(Equal(account.Href))
+	})
+
+	It("should reject an invalid bearer token", func() {
+		r := newRequest("GET", "/private", nil)
+		r.Header.Set("Authorization", "Bearer invalid")
+		r.Header.Set("Accept", stormpath.ApplicationJson)
+
+		w := serve(handler, r)
+
+		Expect(current).To(BeNil())
+		Expect(w.Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should authenticate the access token cookie", func() {
+		login := tokenCookies()
+		r := newRequest("GET", "/private", nil)
+		r.AddCookie(responseCookie(login, DefaultAccessTokenCookieName))
+
+		w := serve(handler, r)
+
+		Expect(current).NotTo(BeNil())
+		Expect(GetCurrentAccount(current).Href).To(Equal(account.Href))
+		Expect(w.Result().Cookies()).To(BeEmpty())
+	})
+
+	It("should refresh the tokens with the refresh token cookie", func() {
+		login := tokenCookies()
+		r := newRequest("GET", "/private", nil)
+		r.AddCookie(&http.Cookie{Name: DefaultAccessTokenCookieName, Value: "expired"})
+		r.AddCookie(responseCookie(login, DefaultRefreshTokenCookieName))
+
+		w := serve(handler, r)
+
+		Expect(current).NotTo(BeNil())
+		Expect(GetCurrentAccount(current).Href).To(Equal(account.Href))
+		Expect(responseCookie(w, DefaultAccessTokenCookieName).Value).NotTo(BeEmpty())
+		Expect(responseCookie(w, DefaultAccessTokenCookieName).Value).NotTo(Equal(responseCookie(login, DefaultAccessTokenCookieName).Value))
+	})
+
+	It("should clear the cookies of an invalid refresh token", func() {
+		r := newRequest("GET", "/private", nil)
+		r.AddCookie(&http.Cookie{Name: DefaultRefreshTokenCookieName, Value: "invalid"})
+
+		w := serve(handler, r)
+
+		Expect(current).To(BeNil())
+		Expect(w.Code).To(Equal(http.StatusFound))
+		Expect(responseCookie(w, DefaultRefreshTokenCookieName).MaxAge).To(BeNumerically("<", 0))
+	})
+})
@@ -0,0 +1,148 @@
+package stormpathweb
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gorilla/sessions"
+	"github.com/sappenin/stormpath-sdk-go"
+)
+
+//DefaultResendVerificationFields are the inputs of the resend verification email form
+var DefaultResendVerificationFields = []FormField{
+	{Name: "login", Label: "Username or Email", Type: "text", Required: true},
+}
+
+//VerifyEmailHandler is an http.Handler for the link of the verification email (?sptoken=...). On GET the token is
+//verified, on success AutoLogin stores the account in the session, then SuccessHook is called and the user is
+//redirected to RedirectURI. If the token is missing or invalid the form to resend the verification email is rendered,
+//it is submitted with a POST. JSON requests get the verified account as a JSON response instead.
+//
+//It requires the application in the request context, see ApplicationHandler.
+type VerifyEmailHandler struct {
+	SessionStore sessions.Store
+	SessionName  string
+	//Template defaults to DefaultVerifyEmailTemplate
+	Template    *template.Template
+	AutoLogin   bool
+	SuccessHook SuccessHook
+	//RedirectURI defaults to "/login"
+	RedirectURI string
+	//AccountStoreHref restricts the account lookup of the resend verification email form to the given account store
+	AccountStoreHref string
+	//SentMessage is displayed once the resend verification email form has been submitted
+	SentMessage string
+}
+
+//ServeHTTP implements the http.Handler interface for the VerifyEmailHandler type
+func (h VerifyEmailHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		h.verify(w, r)
+	case "POST":
+		h.resend(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (h VerifyEmailHandler) verify(w http.ResponseWriter, r *http.Request) {
+	view := h.view()
+	data := FormData{Fields: DefaultResendVerificationFields}
+
+	token := r.URL.Query().Get(sptokenParam)
+	if token == "" {
+		view.render(w, r, http.StatusOK, data)
+		return
+	}
+
+	ctx := ContextFunc(r)
+
+	verified, err := stormpath.VerifyEmailToken(ctx, token)
+	if err != nil {
+		status, _ := errorStatus(err)
+		if status == http.StatusInternalServerError {
+			DefaultErrorHandler(w, r, err)
+			return
+		}
+		view.fail(w, r, status, "The verification link is invalid or has expired.", nil, data)
+		return
+	}
+
+	account, err := stormpath.GetAccount(ctx, verified.Href, stormpath.MakeAccountCriteria())
+	if err != nil {
+		DefaultErrorHandler(w, r, err)
+		return
+	}
+
+	if !succeed(w, r, h.SessionStore, h.SessionName, h.AutoLogin, h.SuccessHook, account) {
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"account": account})
+		return
+	}
+
+	redirect := h.RedirectURI
+	if redirect == "" {
+		redirect = "/login"
+	}
+	http.Redirect(w, r, redirect, http.StatusFound)
+}
+
+func (h VerifyEmailHandler) resend(w http.ResponseWriter, r *http.Request) {
+	app := GetApplication(r)
+	if app == nil {
+		DefaultErrorHandler(w, r, errNoApplication)
+		return
+	}
+
+	view := h.view()
+	data := FormData{Fields: DefaultResendVerificationFields}
+
+	values, err := parseInput(r)
+	if err != nil {
+		view.fail(w, r, http.StatusBadRequest, "Invalid request", nil, data)
+		return
+	}
+
+	if !validCSRFToken(h.SessionStore, h.SessionName, r, values) {
+		view.fail(w, r, http.StatusForbidden, errInvalidCSRFToken.Error(), nil, data)
+		return
+	}
+
+	if missing := missingFields(DefaultResendVerificationFields, values); missing != nil {
+		view.fail(w, r, http.StatusBadRequest, "", missing, data)
+		return
+	}
+
+	//Like the forgot password form the response doesn't tell if the account exists
+	err = app.ResendVerificationEmail(ContextFunc(r), values["login"], h.AccountStoreHref)
+	if err != nil {
+		if status, _ := errorStatus(err); status == http.StatusInternalServerError {
+			DefaultErrorHandler(w, r, err)
+			return
+		}
+	}
+
+	if wantsJSON(r) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	message := h.SentMessage
+	if message == "" {
+		message = "If an unverified account exists for this login, a new verification email has been sent to it."
+	}
+	view.render(w, r, http.StatusOK, FormData{Fields: []FormField{}, Message: message})
+}
+
+func (h VerifyEmailHandler) view() formView {
+	tmpl := h.Template
+	if tmpl == nil {
+		tmpl = DefaultVerifyEmailTemplate
+	}
+	return formView{SessionStore: h.SessionStore, SessionName: h.SessionName, Template: tmpl}
+}
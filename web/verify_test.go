@@ -0,0 +1,61 @@
+package stormpathweb_test
+
+import (
+	"net/http"
+	"net/url"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+)
+
+var _ = Describe("VerifyEmailHandler", func() {
+	handler := VerifyEmailHandler{SessionStore: sessionStore, SessionName: sessionName}
+
+	It("should render the resend form without a token", func() {
+		token, w := getCSRFToken(handler, "/verify")
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring(`name="login"`))
+		Expect(token).NotTo(BeEmpty())
+	})
+
+	It("should return the resend form fields to JSON clients", func() {
+		r := newRequest("GET", "/verify", nil)
+		r.Header.Set("Accept", stormpath.ApplicationJson)
+
+		w := serve(handler, r)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal(stormpath.ApplicationJson))
+	})
+
+	It("should reject an invalid token", func() {
+		w := serve(handler, newRequest("GET", "/verify?sptoken=invalid", nil))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("invalid or has expired"))
+	})
+
+	It("should reject a form post without a valid CSRF token", func() {
+		w := serve(handler, newFormRequest("POST", "/verify", url.Values{"login": {account.Email}}))
+
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should require the login to resend the verification email", func() {
+		w := serve(handler, newJSONRequest("POST", "/verify", map[string]string{}))
+
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+		Expect(w.Body.String()).To(ContainSubstring("Username or Email is required"))
+	})
+
+	It("should respond the same way whether the account exists or not", func() {
+		for _, login := range []string{account.Email, "unknown@test.org"} {
+			w := serve(handler, newJSONRequest("POST", "/verify", map[string]string{"login": login}))
+
+			Expect(w.Code).To(Equal(http.StatusNoContent))
+		}
+	})
+})
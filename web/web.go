@@ -1,11 +1,10 @@
 package stormpathweb
 
 import (
-	"github.com/sappenin/stormpath-sdk-go"
 	"net/http"
-	"google.golang.org/appengine/log"
-	"google.golang.org/appengine"
+
 	gorillia_context "github.com/gorilla/context"
+	"github.com/sappenin/stormpath-sdk-go"
 )
 
 //ApplicationKey is the key of the current application in the context
@@ -14,32 +13,32 @@ const ApplicationKey = "application"
 //AccountKey is the key of the current account in the context and session
 const AccountKey = "account"
 
-//GetApplication returns the application from the context previously set by the ApplicationMiddleware
+//GetApplication returns the application from the request context previously set by ApplicationHandler,
+//or by the deprecated ApplicationMiddleware
 func GetApplication(r *http.Request) *stormpath.Application {
-	ctx := appengine.NewContext(r)
-	app := gorillia_context.Get(r, ApplicationKey)
-	if app == nil {
-		log.Debugf(ctx, "Application did NOT exist in Context with Key '%v'", ApplicationKey)
+	if app := ApplicationFromContext(r.Context()); app != nil {
+		return app
+	}
+
+	app, ok := gorillia_context.Get(r, ApplicationKey).(stormpath.Application)
+	if !ok {
 		return nil
-	} else {
-		application := app.(stormpath.Application)
-		log.Debugf(ctx, "Application DID exist in Context with Key '%v': %#v", application, ApplicationKey)
-		return &application
 	}
+	return &app
 }
 
-//GetCurrentAccount retrieves the current account if any from the request context
+//GetCurrentAccount retrieves the current account if any from the request context previously set by AccountHandler,
+//or by the deprecated AccountMiddleware
 func GetCurrentAccount(r *http.Request) *stormpath.Account {
-	ctx := appengine.NewContext(r)
-	acc := gorillia_context.Get(r, AccountKey)
-	if acc == nil {
-		log.Debugf(ctx, "Account did NOT exist in Context with Key '%v'.", AccountKey)
+	if account := AccountFromContext(r.Context()); account != nil {
+		return account
+	}
+
+	account, ok := gorillia_context.Get(r, AccountKey).(stormpath.Account)
+	if !ok {
 		return nil
-	} else {
-		account := acc.(stormpath.Account)
-		log.Debugf(ctx, "Account DID exist in Context with Key '%v':  %#v", AccountKey, account)
-		return &account
 	}
+	return &account
 }
 
 //IsAuthenticated checks if there is an authenticated user
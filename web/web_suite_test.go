@@ -0,0 +1,155 @@
+package stormpathweb_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	uuid "github.com/nu7hatch/gouuid"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/sappenin/stormpath-sdk-go"
+	. "github.com/sappenin/stormpath-sdk-go/web"
+	"google.golang.org/appengine/aetest"
+)
+
+const (
+	sessionName = "session"
+	password    = "1234567z!A89"
+)
+
+var (
+	cred         stormpath.Credentials
+	app          *stormpath.Application
+	account      *stormpath.Account
+	sessionStore = sessions.NewCookieStore([]byte("0123456789abcdef0123456789abcdef"))
+)
+
+var ctx, done, ctx_err = aetest.NewContext()
+
+func TestStormpathWeb(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Stormpath Web Suite")
+}
+
+func randomName() string {
+	uuid, _ := uuid.NewV4()
+	return uuid.String()
+}
+
+func newTestAccount() *stormpath.Account {
+	name := randomName()
+	email := name + "@test.org"
+	return stormpath.NewAccount(email, password, email, "givenName", "surname")
+}
+
+//newRequest returns a request holding the test application in its context
+func newRequest(method string, target string, body io.Reader) *http.Request {
+	r := httptest.NewRequest(method, target, body)
+	return r.WithContext(WithApplication(ctx, app))
+}
+
+func newFormRequest(method string, target string, values url.Values) *http.Request {
+	r := newRequest(method, target, strings.NewReader(values.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func newJSONRequest(method string, target string, v interface{}) *http.Request {
+	body, _ := json.Marshal(v)
+	r := newRequest(method, target, bytes.NewReader(body))
+	r.Header.Set("Content-Type", stormpath.ApplicationJson)
+	r.Header.Set("Accept", stormpath.ApplicationJson)
+	return r
+}
+
+//serve runs the request through the handler and returns the recorded response
+func serve(handler http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+//withCookies adds the cookies set by a previous response to the request
+func withCookies(r *http.Request, w *httptest.ResponseRecorder) *http.Request {
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+//responseCookie returns the cookie with the given name set by the response or nil
+func responseCookie(w *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
+var csrfTokenInput = regexp.MustCompile(`name="csrf_token" value="([^"]+)"`)
+
+//getCSRFToken renders the form of the handler and returns its CSRF token along with the session cookie response
+func getCSRFToken(handler http.Handler, target string) (string, *httptest.ResponseRecorder) {
+	w := serve(handler, newRequest("GET", target, nil))
+	match := csrfTokenInput.FindStringSubmatch(w.Body.String())
+	Expect(match).To(HaveLen(2))
+	return match[1], w
+}
+
+var _ = BeforeSuite(func() {
+	if ctx_err != nil {
+		panic(ctx_err)
+	}
+
+	var err error
+	cred, err = stormpath.NewDefaultCredentials()
+	if err != nil {
+		panic(err)
+	}
+
+	stormpathBaseURL := os.Getenv("STORMPATH_BASE_URL")
+	if stormpathBaseURL != "" {
+		stormpath.BaseURL = stormpathBaseURL
+	}
+
+	stormpath.Init(cred, nil)
+
+	tenant, err := stormpath.CurrentTenant(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	app = stormpath.NewApplication("app-" + randomName())
+	app.AuthorizedCallbackURIs = []string{"http://localhost:8080/callback"}
+	err = tenant.CreateApplication(ctx, app)
+	if err != nil {
+		panic(err)
+	}
+
+	account = newTestAccount()
+	err = app.RegisterAccount(ctx, account)
+	if err != nil {
+		panic(err)
+	}
+})
+
+var _ = AfterSuite(func() {
+	if app != nil {
+		app.Purge(ctx)
+	}
+
+	//done is nil when the aetest context couldn't be created
+	if done != nil {
+		done()
+	}
+})